@@ -1,88 +1,341 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"os"
 	"strconv"
+	"text/tabwriter"
 	"time"
 
 	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
 	"github.com/spf13/cobra"
 )
 
+// defaultScanConcurrency conexões simultâneas padrão na varredura
+const defaultScanConcurrency = 100
+
+// Códigos de saída da verificação de uma única porta
+const (
+	exitPortOpen     = 0 // aberta (em uso) ou, com --local, livre para bind
+	exitPortClosed   = 2 // conexão recusada
+	exitPortFiltered = 3 // sem resposta até o timeout
+	exitPortError    = 4 // falha de DNS, rota ou rede; com --local, bind negado
+	exitPortInUse    = 5 // com --local, a porta já está em uso
+)
+
+// portExitCodes código de saída de cada estado
+var portExitCodes = map[string]int{
+	portcheck.StateOpen:     exitPortOpen,
+	portcheck.StateClosed:   exitPortClosed,
+	portcheck.StateFiltered: exitPortFiltered,
+	portcheck.StateError:    exitPortError, // também portcheck.BindError
+	portcheck.BindFree:      exitPortOpen,
+	portcheck.BindInUse:     exitPortInUse,
+}
+
 var (
-	portHost    string
-	portTimeout int
+	portHost        string
+	portHostFile    string
+	portTimeout     int
+	portConcurrency int
+	portShowAll     bool
+	portLocal       bool
 )
 
 var portCmd = &cobra.Command{
-	Use:   "port",
+	Use:   "port [portas]",
 	Short: "Verifica se uma porta está em uso",
 	Long: `Verifica se uma porta específica está em uso ou disponível.
 Pode verificar portas locais ou remotas.
 
+Portas podem ser listas e faixas (8000-8100,5432) e hosts podem ser listas,
+blocos CIDR (10.0.0.0/30) ou vir de um arquivo (--host-file, um por linha). Com
+mais de uma combinação de host e porta, a verificação é feita em paralelo e o
+resultado é uma tabela ordenada por host e porta; portas fechadas só aparecem
+com --all.
+
+Estados e códigos de saída (na verificação de uma única porta):
+  0  aberta: a conexão foi estabelecida (porta em uso)
+  2  fechada: a conexão foi recusada (nenhum processo na porta)
+  3  filtrada: sem resposta dentro do timeout (firewall ou host inacessível)
+  4  erro: falha de DNS, rota ou rede
+
+Com --local, a porta é testada com bind na interface de --host (padrão: todas),
+respondendo se um servidor pode ser iniciado nela: 0 livre, 5 em uso e 4 erro
+(ex.: permissão negada para portas abaixo de 1024).
+
+Com --who (apenas Linux), as portas em uso nesta máquina mostram o processo que
+escuta nelas, em TCP e UDP, IPv4 e IPv6: PID, usuário, horário de início e linha
+de comando. Processos de outros usuários só são identificados com privilégios
+(sudo); sem eles, aparece apenas o usuário dono do socket.
+
 Exemplos:
   bast port 8080              # Verifica porta 8080 em localhost
   bast port 3000 --host google.com  # Verifica porta 3000 em google.com
   bast port 22 --timeout 5     # Verifica com timeout de 5 segundos
+  bast port 8000-8100,5432     # Varre as portas em localhost
+  bast port 22,80,443 --host 10.0.0.0/30,db.local --concurrency 50
+  bast port 5432 --host-file hosts.txt --all
+  bast port 8080 --local       # Posso iniciar um servidor na porta 8080?
+  bast port 3000-3010 --local --host 127.0.0.1
+  bast port 8080 --who         # Qual processo está usando a porta 8080?
   bast port --help             # Mostra ajuda deste comando`,
 	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		portStr := args[0]
-		port, err := strconv.Atoi(portStr)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ports, err := portcheck.ParsePorts(args[0])
 		if err != nil {
-			fmt.Printf("Erro: '%s' não é uma porta válida.\n", portStr)
-			verbosePrint(cmd, "Erro ao converter porta: %v\n", err)
-			return
+			return err
 		}
-
-		if port < constants.MinPort || port > constants.MaxPort {
-			fmt.Printf(constants.ErrInvalidPort+"\n", constants.MinPort, constants.MaxPort)
-			return
+		hosts, err := portHosts(cmd)
+		if err != nil {
+			return err
+		}
+		targets, err := portcheck.Targets(hosts, ports)
+		if err != nil {
+			return err
 		}
 
-		verbosePrint(cmd, "Verificando porta %d em %s...\n", port, portHost)
-		checkPort(cmd, port, portHost, portTimeout)
+		if portLocal {
+			return checkLocalPorts(cmd, targets)
+		}
+		if len(targets) == 1 {
+			verbosePrint(cmd, "Verificando porta %d em %s...\n", ports[0], hosts[0])
+			state := checkPort(cmd, ports[0], hosts[0], portTimeout)
+			return exitWithCode(cmd, portExitCodes[state])
+		}
+		scanPorts(cmd, targets, len(hosts))
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(portCmd)
 
-	portCmd.Flags().StringVarP(&portHost, "host", "H", "localhost", "Host para verificar a porta")
+	portCmd.Flags().StringVarP(&portHost, "host", "H", "localhost", "Hosts para verificar: lista separada por vírgula e blocos CIDR")
+	portCmd.Flags().StringVar(&portHostFile, "host-file", "", "Arquivo com hosts, um por linha (soma-se a --host, se informado)")
 	portCmd.Flags().IntVarP(&portTimeout, "timeout", "t", constants.DefaultNetworkTimeout, "Timeout em segundos")
+	portCmd.Flags().IntVarP(&portConcurrency, "concurrency", "c", defaultScanConcurrency, "Conexões simultâneas na varredura")
+	portCmd.Flags().BoolVar(&portShowAll, "all", false, "Inclui as portas fechadas na tabela da varredura")
+	portCmd.Flags().BoolVar(&portLocal, "local", false, "Testa com bind se a porta pode ser usada por um servidor nesta máquina")
+}
+
+// portHosts combina os hosts de --host e --host-file, sem repetições; com
+// --host-file, --host só é usado se informado explicitamente. Com --local, o
+// padrão é testar todas as interfaces
+func portHosts(cmd *cobra.Command) ([]string, error) {
+	var hosts []string
+	if portLocal && !cmd.Flags().Changed("host") && portHostFile == "" {
+		return []string{constants.DefaultHost}, nil
+	}
+	if portHostFile == "" || cmd.Flags().Changed("host") {
+		expanded, err := portcheck.ExpandHosts(portHost)
+		if err != nil {
+			return nil, err
+		}
+		hosts = expanded
+	}
+	if portHostFile != "" {
+		fromFile, err := portcheck.ReadHostsFile(portHostFile)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(hosts))
+		for _, h := range hosts {
+			seen[h] = true
+		}
+		for _, h := range fromFile {
+			if !seen[h] {
+				seen[h] = true
+				hosts = append(hosts, h)
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// scanPorts verifica os alvos em paralelo e imprime a tabela e o resumo
+func scanPorts(cmd *cobra.Command, targets []portcheck.Target, hosts int) {
+	if portConcurrency < 1 {
+		portConcurrency = 1
+	}
+	fmt.Printf("Verificando %d porta(s) em %d host(s)...\n", len(targets), hosts)
+	verbosePrint(cmd, "Concorrência: %d, timeout: %ds\n", portConcurrency, portTimeout)
+
+	start := time.Now()
+	results := portcheck.Scan(context.Background(), targets, portConcurrency, time.Duration(portTimeout)*time.Second)
+	elapsed := time.Since(start)
+	owners := scanOwners(results)
+
+	counts := make(map[string]int)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	rows := 0
+	for _, r := range results {
+		state := r.State()
+		counts[state]++
+		if state == portcheck.StateClosed && !portShowAll {
+			continue
+		}
+		if rows == 0 {
+			fmt.Fprint(tw, "HOST\tPORTA\tESTADO\tLATÊNCIA")
+			if portWho {
+				fmt.Fprint(tw, "\tPROCESSO")
+			}
+			fmt.Fprintln(tw)
+		}
+		rows++
+		latency := "-"
+		if state != portcheck.StateFiltered {
+			latency = r.Duration.Round(10 * time.Microsecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s", r.Host, r.Port, state, latency)
+		if portWho {
+			process := "-"
+			if state == portcheck.StateOpen && isLocalHost(r.Host) {
+				process = ownerSummary(owners[r.Port])
+			}
+			fmt.Fprintf(tw, "\t%s", process)
+		}
+		fmt.Fprintln(tw)
+		verbosePrint(cmd, "%s: %v\n", net.JoinHostPort(r.Host, strconv.Itoa(r.Port)), r.Err)
+	}
+	_ = tw.Flush()
+	if rows == 0 {
+		fmt.Println("Nenhuma porta aberta, filtrada ou com erro.")
+	}
+
+	fmt.Printf("\n%d porta(s) verificada(s) em %v: %d aberta(s), %d fechada(s), %d filtrada(s), %d com erro\n",
+		len(results), elapsed.Round(time.Millisecond),
+		counts[portcheck.StateOpen], counts[portcheck.StateClosed], counts[portcheck.StateFiltered], counts[portcheck.StateError])
 }
 
-func checkPort(cmd *cobra.Command, port int, host string, timeout int) {
-	address := fmt.Sprintf("%s:%d", host, port)
-	verbosePrint(cmd, "Tentando conectar em %s...\n", address)
+// scanOwners consulta, com --who, os processos das portas abertas nos hosts locais
+func scanOwners(results []portcheck.Result) map[int][]portcheck.Owner {
+	if !portWho {
+		return nil
+	}
+	local := make(map[string]bool)
+	var ports []int
+	for _, r := range results {
+		if r.State() != portcheck.StateOpen {
+			continue
+		}
+		isLocal, ok := local[r.Host]
+		if !ok {
+			isLocal = isLocalHost(r.Host)
+			local[r.Host] = isLocal
+		}
+		if isLocal {
+			ports = append(ports, r.Port)
+		}
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	return portOwnersByPort(ports)
+}
+
+// checkLocalPorts testa com bind se as portas podem ser usadas nesta máquina; com
+// um único alvo, o código de saída indica o resultado
+func checkLocalPorts(cmd *cobra.Command, targets []portcheck.Target) error {
+	if len(targets) == 1 {
+		result := portcheck.Bind(targets[0].Host, targets[0].Port)
+		state := result.State()
+		switch state {
+		case portcheck.BindFree:
+			fmt.Printf(constants.SuccessPortFree+"\n", result.Port, result.Host)
+		case portcheck.BindInUse:
+			fmt.Printf(constants.SuccessPortInUse+"\n", result.Port, result.Host)
+			fmt.Printf("   Não é possível iniciar um servidor nela: %v\n", result.Err)
+			if portWho {
+				printPortOwners(result.Port, result.Host)
+			}
+		default:
+			fmt.Printf("Não é possível usar a porta %d em %s\n", result.Port, result.Host)
+			fmt.Printf("   %v\n", result.Err)
+		}
+		return exitWithCode(cmd, portExitCodes[state])
+	}
 
-	timeoutDuration := time.Duration(timeout) * time.Second
-	conn, err := net.DialTimeout(constants.TCPProtocol, address, timeoutDuration)
+	results := make([]portcheck.BindResult, len(targets))
+	var inUse []int
+	for i, target := range targets {
+		results[i] = portcheck.Bind(target.Host, target.Port)
+		if results[i].State() == portcheck.BindInUse {
+			inUse = append(inUse, target.Port)
+		}
+	}
+	var owners map[int][]portcheck.Owner
+	if portWho && len(inUse) > 0 {
+		owners = portOwnersByPort(inUse)
+	}
 
-	if err != nil {
-		// Se não conseguiu conectar, a porta provavelmente está livre
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			fmt.Printf("Timeout ao conectar em %s:%d\n", host, port)
-			fmt.Printf("   A porta pode estar fechada ou o host não está acessível.\n")
-			verbosePrint(cmd, "Timeout após %d segundos.\n", timeout)
-		} else {
-			fmt.Printf(constants.SuccessPortAvailable+"\n", port, host)
-			verbosePrint(cmd, "Erro de conexão (esperado para porta livre): %v\n", err)
+	counts := make(map[string]int)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "HOST\tPORTA\tBIND")
+	if portWho {
+		fmt.Fprint(tw, "\tPROCESSO")
+	}
+	fmt.Fprintln(tw)
+	for _, result := range results {
+		state := result.State()
+		counts[state]++
+		fmt.Fprintf(tw, "%s\t%d\t%s", result.Host, result.Port, state)
+		if portWho {
+			process := "-"
+			if state == portcheck.BindInUse {
+				process = ownerSummary(owners[result.Port])
+			}
+			fmt.Fprintf(tw, "\t%s", process)
+		}
+		fmt.Fprintln(tw)
+		if result.Err != nil {
+			verbosePrint(cmd, "%s: %v\n", result.Address, result.Err)
 		}
-		return
 	}
+	_ = tw.Flush()
+	fmt.Printf("\n%d porta(s) testada(s): %d livre(s), %d em uso, %d com erro\n",
+		len(targets), counts[portcheck.BindFree], counts[portcheck.BindInUse], counts[portcheck.BindError])
+	return nil
+}
+
+// checkPort verifica uma porta e informa o estado encontrado
+func checkPort(cmd *cobra.Command, port int, host string, timeout int) string {
+	verbosePrint(cmd, "Tentando conectar em %s...\n", net.JoinHostPort(host, strconv.Itoa(port)))
 
-	defer conn.Close()
+	result := portcheck.Probe(context.Background(), host, port, time.Duration(timeout)*time.Second)
+
+	state := result.State()
+	switch state {
+	case portcheck.StateFiltered:
+		fmt.Printf("Timeout ao conectar em %s:%d\n", host, port)
+		fmt.Printf("   A porta está filtrada ou o host não está acessível.\n")
+		verbosePrint(cmd, "Timeout após %d segundos.\n", timeout)
+		return state
+	case portcheck.StateClosed:
+		// Conexão recusada: nenhum processo escuta na porta
+		fmt.Printf(constants.SuccessPortAvailable+"\n", port, host)
+		verbosePrint(cmd, "Conexão recusada (esperado para porta livre): %v\n", result.Err)
+		return state
+	case portcheck.StateError:
+		fmt.Printf("Erro ao verificar a porta %d em %s\n", port, host)
+		fmt.Printf("   %v\n", result.Err)
+		fmt.Printf("   Não foi possível determinar o estado da porta (falha de DNS, rota ou rede).\n")
+		return state
+	}
 
 	// Se conseguiu conectar, a porta está em uso
 	fmt.Printf(constants.SuccessPortInUse+"\n", port, host)
-	fmt.Printf("   Endereço: %s\n", address)
+	fmt.Printf("   Endereço: %s\n", result.Address)
 	verbosePrint(cmd, "Conexão estabelecida com sucesso, porta está em uso.\n")
+	if portWho {
+		printPortOwners(port, host)
+	}
 
-	// Tenta obter informações adicionais
-	localAddr := conn.LocalAddr()
-	remoteAddr := conn.RemoteAddr()
-	verbosePrint(cmd, "Endereço local: %s\n", localAddr)
-	verbosePrint(cmd, "Endereço remoto: %s\n", remoteAddr)
+	// Informações adicionais da conexão
+	verbosePrint(cmd, "Endereço local: %s\n", result.LocalAddr)
+	verbosePrint(cmd, "Endereço remoto: %s\n", result.RemoteAddr)
+	return state
 }
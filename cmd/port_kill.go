@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/daemon"
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
+	"github.com/spf13/cobra"
+)
+
+var (
+	portKillYes     bool
+	portKillForce   bool
+	portKillTimeout time.Duration
+)
+
+var portKillCmd = &cobra.Command{
+	Use:   "kill <portas>",
+	Short: "Encerra o processo que escuta na porta",
+	Long: `Encerra o processo que escuta na porta (TCP ou UDP) para liberá-la.
+
+O processo é identificado como em bast port --who e exibido antes da confirmação
+(dispensada com --yes). O encerramento começa com SIGTERM; se o processo não
+terminar dentro de --timeout, recebe SIGKILL. Ao final, a porta é verificada
+novamente. Disponível apenas no Linux.
+
+Por segurança, o PID 1 e processos de outros usuários não são encerrados sem
+--force. Com sudo, "outros usuários" são os diferentes de quem executou o sudo
+(SUDO_UID): os processos do próprio usuário não exigem --force, e os do root sim.
+O dono considerado é o do processo, e não o do socket, que pode ser herdado.
+Depois da confirmação, PIDs que terminaram ou passaram a ser de outro processo
+são ignorados.
+
+Exemplos:
+  bast port kill 8080              # Mostra o processo e pede confirmação
+  bast port kill 3000,5173 --yes   # Sem confirmação
+  bast port kill 8080 --timeout 30s
+  sudo bast port kill 80 --force   # Processo de outro usuário`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ports, err := portcheck.ParsePorts(args[0])
+		if err != nil {
+			return err
+		}
+		return killPortOwners(ports)
+	},
+}
+
+func init() {
+	portCmd.AddCommand(portKillCmd)
+
+	portKillCmd.Flags().BoolVarP(&portKillYes, "yes", "y", false, "Encerra sem pedir confirmação")
+	portKillCmd.Flags().BoolVar(&portKillForce, "force", false, "Permite encerrar o PID 1 e processos de outros usuários")
+	portKillCmd.Flags().DurationVar(&portKillTimeout, "timeout", 10*time.Second, "Tempo de espera após SIGTERM antes de enviar SIGKILL")
+}
+
+// killPortOwners encerra os processos que escutam nas portas e confere se elas
+// foram liberadas
+func killPortOwners(ports []int) error {
+	owners, err := portcheck.FindOwners(ports...)
+	if err != nil {
+		return err
+	}
+	if len(owners) == 0 {
+		fmt.Printf("Nenhum processo escuta na(s) porta(s) %s; nada a encerrar\n", portList(ports))
+		return nil
+	}
+
+	fmt.Printf("Processo(s) na(s) porta(s) %s:\n", portList(ports))
+	printOwnerTable(owners)
+
+	pids, err := killablePIDs(owners)
+	if err != nil {
+		return err
+	}
+
+	if !portKillYes {
+		ok, err := confirm(fmt.Sprintf("Encerrar %d processo(s)?", len(pids)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Operação cancelada")
+			return nil
+		}
+	}
+
+	// A confirmação pode demorar: o processo exibido pode ter terminado e o PID
+	// ter sido reutilizado por outro, que não deve ser sinalizado
+	current, err := portcheck.FindOwners(ports...)
+	if err != nil {
+		return err
+	}
+	pids, changed := unchangedPIDs(pids, owners, current)
+	for _, pid := range changed {
+		fmt.Printf("Processo %d ignorado: terminou ou não é mais o processo exibido\n", pid)
+	}
+
+	for _, pid := range pids {
+		start := time.Now()
+		killed, err := daemon.Stop(pid, portKillTimeout)
+		switch {
+		case errors.Is(err, daemon.ErrNotRunning):
+			fmt.Printf("Processo %d já havia terminado\n", pid)
+		case err != nil:
+			return err
+		case killed:
+			fmt.Printf("Processo %d não encerrou em %v e foi finalizado com SIGKILL\n", pid, portKillTimeout)
+		default:
+			fmt.Printf("Processo %d encerrado em %v\n", pid, time.Since(start).Round(time.Millisecond))
+		}
+	}
+
+	remaining, err := portcheck.FindOwners(ports...)
+	if err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		fmt.Println("Ainda há processos escutando:")
+		printOwnerTable(remaining)
+		return fmt.Errorf("porta(s) %s continua(m) em uso", portList(ports))
+	}
+	fmt.Printf("Porta(s) %s liberada(s)\n", portList(ports))
+	return nil
+}
+
+// killablePIDs retorna os PIDs a encerrar, sem repetições, recusando processos
+// não identificados e, sem --force, o PID 1, o próprio bast e processos de
+// outros usuários (pelo dono do processo, não do socket, que pode ser herdado)
+func killablePIDs(owners []portcheck.Owner) ([]int, error) {
+	seen := make(map[int]bool)
+	var pids []int
+	for _, o := range owners {
+		if seen[o.PID] {
+			continue
+		}
+		seen[o.PID] = true
+		switch {
+		case o.PID == 0:
+			return nil, fmt.Errorf("o processo em %s pertence ao usuário %s e não pôde ser identificado; execute com sudo", o.Address(), o.User)
+		case o.PID == os.Getpid():
+			return nil, fmt.Errorf("o processo %d é o próprio bast", o.PID)
+		case o.PID == 1 && !portKillForce:
+			return nil, fmt.Errorf("o processo %d é o init do sistema ou do contêiner; use --force para encerrá-lo mesmo assim", o.PID)
+		case o.ProcessUID < 0 && !portKillForce:
+			return nil, fmt.Errorf("não foi possível identificar o dono do processo %d; use --force para encerrá-lo mesmo assim", o.PID)
+		case o.ProcessUID >= 0 && o.ProcessUID != invokingUID() && !portKillForce:
+			return nil, fmt.Errorf("o processo %d pertence ao usuário %s; use --force para encerrá-lo mesmo assim", o.PID, o.User)
+		}
+		pids = append(pids, o.PID)
+	}
+	return pids, nil
+}
+
+// unchangedPIDs separa os PIDs que continuam escutando nas portas com o mesmo
+// horário de início (before, exibido na confirmação; after, lido de novo) dos
+// que terminaram ou tiveram o PID reutilizado
+func unchangedPIDs(pids []int, before, after []portcheck.Owner) (kept, changed []int) {
+	started := func(owners []portcheck.Owner, pid int) (time.Time, bool) {
+		for _, o := range owners {
+			if o.PID == pid {
+				return o.Started, true
+			}
+		}
+		return time.Time{}, false
+	}
+	for _, pid := range pids {
+		was, _ := started(before, pid)
+		now, ok := started(after, pid)
+		if ok && now.Equal(was) {
+			kept = append(kept, pid)
+		} else {
+			changed = append(changed, pid)
+		}
+	}
+	return kept, changed
+}
+
+// invokingUID retorna o usuário que executou o bast: com sudo, o de SUDO_UID, e
+// não o root
+func invokingUID() int {
+	uid := os.Getuid()
+	if uid != 0 {
+		return uid
+	}
+	if sudoUID, err := strconv.Atoi(os.Getenv("SUDO_UID")); err == nil {
+		return sudoUID
+	}
+	return uid
+}
+
+// confirm pergunta sim ou não no terminal; sem terminal, exige --yes
+func confirm(question string) (bool, error) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false, errors.New("entrada não interativa: use --yes para confirmar")
+	}
+	fmt.Printf("%s [s/N] ", question)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return false, fmt.Errorf("erro ao ler resposta: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "s", "sim", "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// portList lista as portas para as mensagens, separadas por vírgula
+func portList(ports []int) string {
+	list := make([]string, len(ports))
+	for i, p := range ports {
+		list[i] = strconv.Itoa(p)
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setPortKillForce define --force durante o teste
+func setPortKillForce(t *testing.T, force bool) {
+	previous := portKillForce
+	portKillForce = force
+	t.Cleanup(func() { portKillForce = previous })
+}
+
+// processOwner dono de porta com o processo pertencente a uid
+func processOwner(pid, uid int) portcheck.Owner {
+	return portcheck.Owner{PID: pid, ProcessUID: uid, User: "usuario"}
+}
+
+func TestKillablePIDs(t *testing.T) {
+	t.Setenv("SUDO_UID", "")
+	uid := os.Getuid()
+	other := uid + 1
+	setPortKillForce(t, false)
+
+	// Processos do próprio usuário, sem repetições, mesmo com o socket de outro dono
+	own := processOwner(4242, uid)
+	own.UID = other
+	pids, err := killablePIDs([]portcheck.Owner{own, own, processOwner(4243, uid)})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4242, 4243}, pids)
+
+	_, err = killablePIDs([]portcheck.Owner{processOwner(1, uid)})
+	assert.ErrorContains(t, err, "--force")
+
+	_, err = killablePIDs([]portcheck.Owner{processOwner(4244, other)})
+	assert.ErrorContains(t, err, "--force")
+	_, err = killablePIDs([]portcheck.Owner{processOwner(4245, -1)})
+	assert.ErrorContains(t, err, "--force")
+
+	_, err = killablePIDs([]portcheck.Owner{{ProcessUID: -1, User: "root", Err: portcheck.ErrOwnerHidden}})
+	assert.ErrorContains(t, err, "execute com sudo")
+
+	_, err = killablePIDs([]portcheck.Owner{processOwner(os.Getpid(), uid)})
+	assert.ErrorContains(t, err, "próprio bast")
+
+	setPortKillForce(t, true)
+	pids, err = killablePIDs([]portcheck.Owner{processOwner(1, uid), processOwner(4244, other)})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4244}, pids)
+	_, err = killablePIDs([]portcheck.Owner{processOwner(os.Getpid(), uid)})
+	assert.Error(t, err)
+}
+
+func TestKillablePIDsUnderSudo(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requer execução como root")
+	}
+	setPortKillForce(t, false)
+	t.Setenv("SUDO_UID", "1000")
+	assert.Equal(t, 1000, invokingUID())
+
+	// Processos de quem executou o sudo não exigem --force; os do root, sim
+	pids, err := killablePIDs([]portcheck.Owner{processOwner(4242, 1000)})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4242}, pids)
+
+	_, err = killablePIDs([]portcheck.Owner{processOwner(4343, 0)})
+	assert.ErrorContains(t, err, "--force")
+}
+
+func TestUnchangedPIDs(t *testing.T) {
+	started := time.Unix(1700000000, 0)
+	owner := func(pid int, started time.Time) portcheck.Owner {
+		return portcheck.Owner{PID: pid, Started: started}
+	}
+	before := []portcheck.Owner{owner(10, started), owner(11, started), owner(12, started)}
+	after := []portcheck.Owner{owner(10, started), owner(11, started.Add(time.Second))}
+
+	kept, changed := unchangedPIDs([]int{10, 11, 12}, before, after)
+	assert.Equal(t, []int{10}, kept)
+	assert.Equal(t, []int{11, 12}, changed)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
+	"github.com/spf13/cobra"
+)
+
+// Códigos de saída da espera, os mesmos do utilitário timeout(1) e do shell
+const (
+	exitWaitTimeout     = 124 // o timeout expirou antes de todos os alvos ficarem prontos
+	exitWaitInterrupted = 130 // espera interrompida por sinal (Ctrl+C)
+)
+
+// waitDialTimeout timeout de cada tentativa de conexão durante a espera
+const waitDialTimeout = 2 * time.Second
+
+var (
+	portWaitTimeout     time.Duration
+	portWaitInterval    time.Duration
+	portWaitMaxInterval time.Duration
+	portWaitClosed      bool
+	portWaitQuiet       bool
+)
+
+var portWaitCmd = &cobra.Command{
+	Use:   "wait <host:porta>... [-- comando [argumentos]]",
+	Short: "Aguarda portas aceitarem conexões e executa um comando",
+	Long: `Aguarda até que todos os alvos aceitem conexões TCP (ou, com --closed, até
+que todos deixem de aceitar) e então executa o comando informado depois de --,
+no lugar do bast (mesmo PID, ideal como entrypoint de contêineres).
+
+Alvos sem host, como 8080 ou :8080, usam localhost. As tentativas são feitas em
+rodadas, começando em --interval e dobrando até --max-interval, com variação
+aleatória. O progresso é exibido na saída de erro, para não se misturar à saída
+do comando.
+
+Se o timeout expirar antes, nenhum comando é executado e o código de saída é
+124 (130 se a espera for interrompida). Com --timeout 0, a espera não tem limite.
+
+Exemplos:
+  bast port wait db:5432 redis:6379 --timeout 60s -- ./start.sh
+  bast port wait 8080 --interval 200ms          # Espera o servidor local subir
+  bast port wait api:80 --closed --timeout 30s  # Espera o serviço parar
+  bast port wait db:5432 -q -- npm start`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if dash := cmd.ArgsLenAtDash(); dash == 0 || dash < 0 && len(args) == 0 {
+			return errors.New("informe ao menos um alvo host:porta")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		specs, command := args, []string(nil)
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			specs, command = args[:dash], args[dash:]
+		}
+		targets := make([]portcheck.Target, 0, len(specs))
+		for _, spec := range specs {
+			target, err := portcheck.ParseTarget(spec, "localhost")
+			if err != nil {
+				return err
+			}
+			targets = append(targets, target)
+		}
+		if err := waitPorts(cmd, targets); err != nil {
+			return err
+		}
+		if len(command) == 0 {
+			return nil
+		}
+		return execCommand(cmd, command)
+	},
+}
+
+func init() {
+	portCmd.AddCommand(portWaitCmd)
+
+	portWaitCmd.Flags().DurationVar(&portWaitTimeout, "timeout", 30*time.Second, "Tempo máximo de espera (0 para esperar indefinidamente)")
+	portWaitCmd.Flags().DurationVar(&portWaitInterval, "interval", 500*time.Millisecond, "Intervalo inicial entre tentativas")
+	portWaitCmd.Flags().DurationVar(&portWaitMaxInterval, "max-interval", 5*time.Second, "Intervalo máximo entre tentativas")
+	portWaitCmd.Flags().BoolVar(&portWaitClosed, "closed", false, "Espera os alvos deixarem de aceitar conexões")
+	portWaitCmd.Flags().BoolVarP(&portWaitQuiet, "quiet", "q", false, "Não exibe o progresso")
+}
+
+// waitPorts aguarda os alvos, exibindo o progresso na saída de erro
+func waitPorts(cmd *cobra.Command, targets []portcheck.Target) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if portWaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, portWaitTimeout)
+		defer cancel()
+	}
+
+	expected, reached := "aceitar(em) conexões", "aceitando conexões"
+	if portWaitClosed {
+		expected, reached = "deixar(em) de aceitar conexões", "não aceita mais conexões"
+	}
+	limit := "sem limite de tempo"
+	if portWaitTimeout > 0 {
+		limit = "timeout de " + portWaitTimeout.String()
+	}
+	waitProgress("Aguardando %s %s (%s)...\n", targetList(targets), expected, limit)
+
+	start := time.Now()
+	pending, err := portcheck.Wait(ctx, targets, portcheck.WaitOptions{
+		Closed:      portWaitClosed,
+		Interval:    portWaitInterval,
+		MaxInterval: portWaitMaxInterval,
+		DialTimeout: waitDialTimeout,
+		OnReady: func(r portcheck.Result, elapsed time.Duration) {
+			waitProgress("%s %s após %v\n", r.Address, reached, elapsed.Round(time.Millisecond))
+		},
+		OnRetry: func(pending []portcheck.Result, attempt int, next time.Duration) {
+			waitProgress("Tentativa %d: aguardando %s, próxima em %v\n",
+				attempt, resultList(pending), next.Round(time.Millisecond))
+		},
+	})
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintf(os.Stderr, "Timeout de %v atingido; sem resposta esperada de %s\n", portWaitTimeout, resultList(pending))
+		return exitWithCode(cmd, exitWaitTimeout)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Espera interrompida")
+		return exitWithCode(cmd, exitWaitInterrupted)
+	}
+	waitProgress("Todos os alvos prontos em %v\n", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// waitProgress exibe o andamento na saída de erro, exceto com --quiet
+func waitProgress(format string, args ...interface{}) {
+	if !portWaitQuiet {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// targetList lista os alvos no formato host:porta
+func targetList(targets []portcheck.Target) string {
+	list := make([]string, len(targets))
+	for i, t := range targets {
+		list[i] = net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+	}
+	return strings.Join(list, ", ")
+}
+
+// resultList lista os alvos pendentes com o estado da última tentativa
+func resultList(results []portcheck.Result) string {
+	list := make([]string, len(results))
+	for i, r := range results {
+		list[i] = fmt.Sprintf("%s (%s)", r.Address, r.State())
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// execCommand substitui o bast pelo comando, que herda o PID, o ambiente e a
+// entrada e saída; só retorna em caso de erro
+func execCommand(cmd *cobra.Command, args []string) error {
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf("comando não encontrado: %w", err)
+	}
+	if err := syscall.Exec(path, args, os.Environ()); err != nil {
+		return fmt.Errorf("erro ao executar %s: %w", args[0], err)
+	}
+	return nil
+}
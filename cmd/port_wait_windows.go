@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+)
+
+// execCommand executa o comando como processo filho, já que o Windows não
+// substitui o processo atual, e encerra o bast com o mesmo código de saída
+func execCommand(cmd *cobra.Command, args []string) error {
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitWithCode(cmd, exitErr.ExitCode())
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao executar %s: %w", args[0], err)
+	}
+	return nil
+}
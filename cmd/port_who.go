@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
+)
+
+var portWho bool
+
+func init() {
+	portCmd.Flags().BoolVar(&portWho, "who", false, "Mostra o processo que escuta na porta (PID, usuário, início e comando; apenas Linux)")
+}
+
+// isLocalHost verifica se o host se refere a esta máquina: localhost, o nome da
+// máquina, endereços de loopback, não especificados ou de uma interface local
+func isLocalHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	if name, err := os.Hostname(); err == nil && strings.EqualFold(host, name) {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// printPortOwners imprime os processos que escutam na porta, abaixo da mensagem
+// de porta em uso
+func printPortOwners(port int, host string) {
+	if !isLocalHost(host) {
+		fmt.Printf("   --who identifica apenas processos desta máquina; %s é um host remoto.\n", host)
+		return
+	}
+	owners, err := portcheck.FindOwners(port)
+	if err != nil {
+		fmt.Printf("   Não foi possível identificar o processo: %v\n", err)
+		return
+	}
+	if len(owners) == 0 {
+		fmt.Printf("   Nenhum processo desta máquina escuta na porta %d (pode estar em outro contêiner ou namespace de rede).\n", port)
+		return
+	}
+
+	fmt.Printf("   Processo(s) na porta %d:\n", port)
+	printOwnerTable(owners)
+}
+
+// printOwnerTable imprime os processos, um por socket, com recuo
+func printOwnerTable(owners []portcheck.Owner) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   PID\tUSUÁRIO\tINÍCIO\tSOCKET\tCOMANDO")
+	for _, o := range owners {
+		pid, started, command := "?", "-", o.Command
+		if o.PID != 0 {
+			pid = strconv.Itoa(o.PID)
+		}
+		if !o.Started.IsZero() {
+			started = o.Started.Format(time.DateTime)
+		}
+		if o.Err != nil {
+			command = "(" + o.Err.Error() + ")"
+		}
+		fmt.Fprintf(tw, "   %s\t%s\t%s\t%s %s\t%s\n", pid, o.User, started, o.Protocol, o.Address(), command)
+	}
+	_ = tw.Flush()
+}
+
+// portOwnersByPort consulta de uma vez os processos das portas, para as tabelas
+// de varredura; retorna nil se a consulta não for possível
+func portOwnersByPort(ports []int) map[int][]portcheck.Owner {
+	owners, err := portcheck.FindOwners(ports...)
+	if err != nil {
+		appLog.Warnf("Não foi possível identificar os processos: %v", err)
+		return nil
+	}
+	byPort := make(map[int][]portcheck.Owner)
+	for _, o := range owners {
+		byPort[o.Port] = append(byPort[o.Port], o)
+	}
+	return byPort
+}
+
+// ownerSummary resume os processos de uma porta para a coluna PROCESSO: PID e
+// nome do executável, sem repetir processos com vários sockets na porta
+func ownerSummary(owners []portcheck.Owner) string {
+	if len(owners) == 0 {
+		return "-"
+	}
+	seen := make(map[int]bool)
+	var parts []string
+	for _, o := range owners {
+		if seen[o.PID] {
+			continue
+		}
+		seen[o.PID] = true
+		if o.PID == 0 {
+			parts = append(parts, "? ("+o.User+")")
+			continue
+		}
+		name := "?"
+		if fields := strings.Fields(o.Command); len(fields) > 0 {
+			name = filepath.Base(fields[0])
+		}
+		parts = append(parts, strconv.Itoa(o.PID)+" "+name)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -76,9 +77,34 @@ Exemplos:
 	},
 }
 
+// exitCodeError encerra o programa com um código de saída específico, sem
+// mensagem de erro; usado por comandos cujo resultado é informado pelo código
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("código de saída %d", e.code)
+}
+
+// exitWithCode retorna o erro que encerra com o código informado (nil para 0),
+// sem exibir a ajuda do comando
+func exitWithCode(cmd *cobra.Command, code int) error {
+	if code == 0 {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return &exitCodeError{code: code}
+}
+
 // Execute adiciona todos os comandos filhos ao comando raiz e define flags apropriadas.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		appLog.Errorf("Erro ao executar comando: %v", err)
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
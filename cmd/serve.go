@@ -1,75 +1,488 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
 	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
 	"github.com/spf13/cobra"
 )
 
 var (
-	port     string
-	host     string
-	endpoint string
+	port         string
+	host         string
+	endpoint     string
+	serveTimeout int
+	gracePeriod  time.Duration
+	serveDir     string
+	serveList    bool
+	serveSPA     bool
+	serveRoutes  string
+	serveInspect bool
+	inspectSize  int
+	accessLog    string
 )
 
+// serveSetting valor efetivo de uma configuração do servidor e sua origem
+type serveSetting struct {
+	Value  string
+	Source string
+}
+
+// serveSettings configurações efetivas do servidor
+type serveSettings struct {
+	Host     serveSetting
+	Port     serveSetting
+	Timeout  serveSetting
+	Endpoint serveSetting
+
+	timeout time.Duration
+}
+
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Inicia um servidor HTTP",
 	Long: `Inicia um servidor HTTP na porta especificada.
 Por padrão, o servidor roda na porta 8080.
 
+Host, porta e timeout são resolvidos com a precedência:
+flag > variável de ambiente (BAST_SERVER_*) > arquivo de configuração > padrão.
+O timeout (server.timeout) define os timeouts de leitura e escrita; o timeout
+de conexões ociosas é quatro vezes esse valor.
+
+Ao receber SIGINT (Ctrl+C) ou SIGTERM, o servidor para de aceitar conexões
+e aguarda as requisições ativas terminarem dentro do período de graça
+(padrão: o timeout do servidor).
+
 Exemplos:
   bast serve                      # Inicia na porta 8080 (padrão)
   bast serve --port 3000         # Inicia na porta 3000
   bast serve -p 3000 -H localhost # Inicia na porta 3000 em localhost
+  bast serve --port auto --port-json  # Primeira porta livre a partir da padrão, informada em JSON
+  bast serve -p 3000 --port-fallback --port-range 3000-3010 --port-file .porta  # 3001, 3002... se 3000 estiver ocupada
+  bast serve --endpoint /app     # Monta o handler principal em /app/
+  bast serve --timeout 60        # Timeouts de leitura/escrita de 60s
+  bast serve --grace 10s         # Aguarda até 10s no encerramento
+  bast serve --dir ./dist        # Serve os arquivos do diretório ./dist
+  bast serve --dir ./docs --listing  # Serve ./docs com listagem de diretórios
+  bast serve --dir ./build --spa # Serve uma SPA com fallback para index.html
+  bast serve --dir ./site --live-reload  # Recarrega o navegador ao salvar arquivos (CSS sem recarregar)
+  bast serve --routes mock.yaml  # API mock declarada em mock.yaml (GET /__routes lista as rotas)
+  bast serve --inspect           # Captura webhooks (página em /__inspect, API em /__requests)
+  bast serve --proxy /api=http://localhost:3000 --proxy /=./dist  # Proxy reverso por prefixo
+  bast serve --proxy /api=http://a:3000,http://b:3000 --proxy-strategy failover  # Vários upstreams
+  bast serve --record https://api.exemplo.com --cassette ./cassetes  # Grava o tráfego com o backend
+  bast serve --replay ./cassetes --match method,path,body  # Responde offline a partir das gravações
+  bast serve --upload-dir ./recebidos --upload-max-size 500MB  # Página e endpoint para receber arquivos
+  bast serve --ws --sse          # WebSocket em /ws/echo e /ws/broadcast, eventos em /sse
+  tail -f app.log | bast serve --sse-stdin  # Cada linha da entrada padrão vira um evento em /sse
+  bast serve --admin-port 9090   # Métricas Prometheus em :9090/metrics, fora da porta pública
+  bast serve --dir . --allow 192.168.0.0/16 --auth-token segredo  # Restringe acesso
+  bast serve hash-password       # Gera hash bcrypt para server.auth.users
+  bast serve --routes mock.yaml --cors  # API mock acessível de outras origens
+  bast serve --dir ./dist --compress --security-headers  # Compressão e cabeçalhos de segurança
+  bast serve --rate-limit 5 --max-body-size 1MB  # 429 acima de 5 req/s por IP, 413 acima de 1MB
+  bast serve --routes mock.yaml --chaos-latency uniform:50ms,300ms --chaos-error-rate 0.1 --seed 42  # Injeção de falhas
+  bast serve --access-log combined  # Log de acesso no formato Apache combined
+  bast serve --tls-self-signed   # HTTPS com certificado autoassinado para localhost
+  bast serve --tls-cert c.pem --tls-key k.pem --tls-redirect-port 8000  # HTTPS + redirecionamento
+  BAST_SERVER_DEFAULT_PORT=3000 bast serve  # Porta via variável de ambiente
+  bast serve start docs -- --dir ./docs -p 3000  # Em segundo plano (veja serve status|logs|stop)
   bast serve --help              # Mostra ajuda deste comando`,
-	Run: func(cmd *cobra.Command, args []string) {
-		startServer(cmd)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return startServer(cmd)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
 
-	serveCmd.Flags().StringVarP(&port, "port", "p", strconv.Itoa(constants.DefaultPort), "Porta do servidor")
-	serveCmd.Flags().StringVarP(&host, "host", "H", constants.DefaultHost, "Host do servidor")
+	serveCmd.Flags().StringVarP(&port, "port", "p", "", "Porta do servidor ou auto para a primeira livre (padrão: server.default_port)")
+	serveCmd.Flags().StringVarP(&host, "host", "H", "", "Host do servidor (padrão: server.default_host)")
 	serveCmd.Flags().StringVarP(&endpoint, "endpoint", "e", "/", "Endpoint principal")
+	serveCmd.Flags().IntVar(&serveTimeout, "timeout", 0, "Timeout de leitura/escrita em segundos (padrão: server.timeout)")
+	serveCmd.Flags().DurationVar(&gracePeriod, "grace", 0, "Período de graça no encerramento (padrão: timeout do servidor)")
+	serveCmd.Flags().StringVarP(&serveDir, "dir", "d", "", "Diretório a ser servido como arquivos estáticos")
+	serveCmd.Flags().BoolVar(&serveList, "listing", false, "Gera listagem HTML para diretórios sem index.html (requer --dir)")
+	serveCmd.Flags().BoolVar(&serveSPA, "spa", false, "Responde index.html para rotas desconhecidas (requer --dir)")
+	serveCmd.Flags().StringVar(&serveRoutes, "routes", "", "Arquivo YAML de rotas para o modo de API mock")
+	serveCmd.Flags().BoolVar(&serveInspect, "inspect", false, "Modo inspetor: registra qualquer requisição (veja /__inspect)")
+	serveCmd.Flags().StringVar(&accessLog, "access-log", server.AccessLogStructured,
+		"Formato do log de acesso: structured (logging.format), combined (Apache) ou off")
+	serveCmd.Flags().IntVar(&inspectSize, "inspect-size", server.DefaultInspectCapacity, "Quantidade de requisições guardadas pelo inspetor")
 }
 
-func startServer(cmd *cobra.Command) {
-	addr := fmt.Sprintf("%s:%s", host, port)
+func startServer(cmd *cobra.Command) error {
+	prepareDaemonLogging()
+	preparePortReport()
+	settings, err := resolveServeSettings(cmd)
+	if err != nil {
+		return err
+	}
 
 	verbosePrint(cmd, "Configurando servidor HTTP...\n")
-	verbosePrint(cmd, "Host: %s\n", host)
-	verbosePrint(cmd, "Porta: %s\n", port)
-	verbosePrint(cmd, "Endpoint principal: %s\n", endpoint)
 
-	http.HandleFunc("/", handler)
-	http.HandleFunc("/health", healthHandler)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mainHandler, err := buildMainHandler(ctx, cmd, mux)
+	if err != nil {
+		return err
+	}
+	mountHandler(mux, settings.Endpoint.Value, mainHandler)
+	mux.HandleFunc("/health", healthHandler)
+	if err := registerHealthChecks(cmd, mux); err != nil {
+		return err
+	}
+	if err := registerRealtime(ctx, cmd, mux); err != nil {
+		return err
+	}
+
+	adminMux, err := newAdminMux(mux)
+	if err != nil {
+		return err
+	}
+	metrics, metricsMiddleware := registerMetrics(adminMux)
+	limits, err := newServeLimits(cmd)
+	if err != nil {
+		return err
+	}
+	limits.register(adminMux, metrics)
+	chaos, err := newChaos(cmd, adminMux)
+	if err != nil {
+		return err
+	}
+
+	accessLogMiddleware, err := newAccessLogMiddleware()
+	if err != nil {
+		return err
+	}
+	headerMiddlewares, err := newHeaderMiddlewares(cmd)
+	if err != nil {
+		return err
+	}
+	authMiddleware, err := newAuthMiddleware(cmd)
+	if err != nil {
+		return err
+	}
+	// O CORS vem antes dos limites e da autenticação para que preflights sejam
+	// respondidos e as recusas (429, 401) cheguem legíveis ao navegador
+	middlewares := append([]server.Middleware{server.RequestID(), accessLogMiddleware, metricsMiddleware}, headerMiddlewares...)
+	middlewares = append(middlewares, limits.rate.Middleware(), limits.body, authMiddleware, chaos.Middleware())
+
+	tlsConfig, err := buildTLSConfig(cmd)
+	if err != nil {
+		return err
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	httpServer := &http.Server{
+		TLSConfig:    tlsConfig,
+		Handler:      server.Chain(mux, middlewares...),
+		ReadTimeout:  settings.timeout,
+		WriteTimeout: settings.timeout,
+		IdleTimeout:  4 * settings.timeout,
+	}
+	srv := server.New(httpServer)
+	srv.ConnLimiter = limits.conns
+
+	grace := gracePeriod
+	if grace <= 0 {
+		grace = settings.timeout
+	}
+
+	verbosePrint(cmd, "ReadTimeout: %v\n", httpServer.ReadTimeout)
+	verbosePrint(cmd, "WriteTimeout: %v\n", httpServer.WriteTimeout)
+	verbosePrint(cmd, "IdleTimeout: %v\n", httpServer.IdleTimeout)
+	verbosePrint(cmd, "Período de graça: %v\n", grace)
 
-	srv := &http.Server{
-		Addr:         addr,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	ln, err := listenServe(settings)
+	if err != nil {
+		return err
 	}
+	defer ln.Close()
+	addr := net.JoinHostPort(settings.Host.Value, settings.Port.Value)
 
-	verbosePrint(cmd, "ReadTimeout: %v\n", srv.ReadTimeout)
-	verbosePrint(cmd, "WriteTimeout: %v\n", srv.WriteTimeout)
-	verbosePrint(cmd, "IdleTimeout: %v\n", srv.IdleTimeout)
+	printServeSettings(settings)
+	redirectWG, err := startRedirectServer(ctx, settings.Host.Value, settings.Port.Value, grace)
+	if err != nil {
+		return err
+	}
+	adminWG, err := startAdminServer(ctx, settings.Host.Value, server.Chain(adminMux, authMiddleware), grace)
+	if err != nil {
+		return err
+	}
 
-	log.Printf("Servidor iniciando em http://%s", addr)
-	log.Printf("Endpoint principal: %s", endpoint)
+	unpublish, err := publishDaemonInstance(scheme, settings.Host.Value, ln.Addr())
+	if err != nil {
+		return err
+	}
+	defer unpublish()
+	removePortFile, err := reportPort(scheme, settings.Host.Value, ln.Addr())
+	if err != nil {
+		return err
+	}
+	defer removePortFile()
+
+	appLog.Infof("Servidor iniciando em %s://%s", scheme, addr)
 	verbosePrint(cmd, "Servidor pronto para receber conexões.\n")
-	if err := srv.ListenAndServe(); err != nil {
-		verbosePrint(cmd, "Erro ao iniciar servidor: %v\n", err)
-		log.Fatal(err)
+
+	srv.BeforeShutdown = func(inFlight int64) {
+		appLog.Infof("Sinal de encerramento recebido, aguardando %d requisição(ões) ativa(s) por até %v...",
+			inFlight, grace)
+	}
+
+	report, err := srv.Serve(ctx, ln, grace)
+	// Encerra também os listeners auxiliares, inclusive em caso de erro
+	stop()
+	redirectWG.Wait()
+	adminWG.Wait()
+	if err != nil {
+		verbosePrint(cmd, "Erro no servidor: %v\n", err)
+		return fmt.Errorf("erro ao executar servidor: %w", err)
+	}
+	if report != nil {
+		printShutdownReport(report, grace)
+	}
+	return nil
+}
+
+// resolveServeSettings resolve as configurações do servidor com a precedência
+// flag > variável de ambiente > arquivo de configuração > constante
+func resolveServeSettings(cmd *cobra.Command) (*serveSettings, error) {
+	cfg := config.Get()
+	settings := &serveSettings{}
+
+	settings.Host = resolveSetting(cmd, "host", host, "server.default_host", cfg.Server.DefaultHost, constants.DefaultHost)
+
+	settings.Port = resolveSetting(cmd, "port", port, "server.default_port",
+		intSettingValue(cfg.Server.DefaultPort), strconv.Itoa(constants.DefaultPort))
+	if settings.Port.Value != portAuto {
+		portNum, err := strconv.Atoi(settings.Port.Value)
+		if err != nil {
+			return nil, fmt.Errorf("porta inválida '%s' (%s): use um número ou auto", settings.Port.Value, settings.Port.Source)
+		}
+		if portNum < constants.MinPort || portNum > constants.MaxPort {
+			return nil, fmt.Errorf(constants.ErrInvalidPort+" (%s)", constants.MinPort, constants.MaxPort, settings.Port.Source)
+		}
+	}
+
+	timeoutFlag := ""
+	if cmd.Flags().Changed("timeout") {
+		timeoutFlag = strconv.Itoa(serveTimeout)
+	}
+	settings.Timeout = resolveSetting(cmd, "timeout", timeoutFlag, "server.timeout",
+		intSettingValue(cfg.Server.Timeout), strconv.Itoa(constants.DefaultTimeout))
+	timeoutSecs, err := strconv.Atoi(settings.Timeout.Value)
+	if err != nil || timeoutSecs <= 0 {
+		return nil, fmt.Errorf("timeout inválido '%s' (%s): deve ser um número positivo de segundos",
+			settings.Timeout.Value, settings.Timeout.Source)
+	}
+	settings.timeout = time.Duration(timeoutSecs) * time.Second
+	settings.Timeout.Value = settings.timeout.String()
+
+	settings.Endpoint = serveSetting{Value: normalizeEndpoint(endpoint), Source: config.SourceDefault}
+	if cmd.Flags().Changed("endpoint") {
+		settings.Endpoint.Source = config.SourceFlag
+	}
+
+	return settings, nil
+}
+
+// resolveSetting aplica a precedência a um único valor: a flag, se informada;
+// senão o valor carregado pelo Viper (env ou arquivo); senão a constante
+func resolveSetting(cmd *cobra.Command, flagName, flagValue, key, cfgValue, fallback string) serveSetting {
+	if cmd.Flags().Changed(flagName) && flagValue != "" {
+		return serveSetting{Value: flagValue, Source: config.SourceFlag}
+	}
+	if cfgValue != "" {
+		return serveSetting{Value: cfgValue, Source: config.Source(key)}
+	}
+	return serveSetting{Value: fallback, Source: config.SourceDefault}
+}
+
+// intSettingValue converte um inteiro da configuração, tratando zero como ausente
+func intSettingValue(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+// normalizeEndpoint garante que o endpoint comece e termine com barra
+func normalizeEndpoint(ep string) string {
+	ep = strings.TrimSpace(ep)
+	if !strings.HasPrefix(ep, "/") {
+		ep = "/" + ep
+	}
+	if !strings.HasSuffix(ep, "/") {
+		ep += "/"
+	}
+	return ep
+}
+
+// mountHandler monta o handler no endpoint informado, removendo o prefixo do caminho
+func mountHandler(mux *http.ServeMux, ep string, h http.Handler) {
+	if ep == "/" {
+		mux.Handle("/", h)
+		return
+	}
+	mux.Handle(ep, http.StripPrefix(strings.TrimSuffix(ep, "/"), h))
+}
+
+// printServeSettings exibe as configurações efetivas e suas origens
+func printServeSettings(settings *serveSettings) {
+	appLog.Infof("Configurações efetivas:")
+	appLog.Infof("  Host:     %-16s (%s)", settings.Host.Value, settings.Host.Source)
+	appLog.Infof("  Porta:    %-16s (%s)", settings.Port.Value, settings.Port.Source)
+	appLog.Infof("  Timeout:  %-16s (%s)", settings.Timeout.Value, settings.Timeout.Source)
+	appLog.Infof("  Endpoint: %-16s (%s)", settings.Endpoint.Value, settings.Endpoint.Source)
+}
+
+// newAccessLogMiddleware cria o middleware de log de acesso no formato escolhido
+func newAccessLogMiddleware() (server.Middleware, error) {
+	switch accessLog {
+	case server.AccessLogStructured, server.AccessLogCombined, server.AccessLogOff:
+		return server.AccessLog(appLog, accessLog, appLog.Out), nil
+	default:
+		return nil, fmt.Errorf("formato de log de acesso inválido '%s': use structured, combined ou off", accessLog)
+	}
+}
+
+// buildMainHandler escolhe o handler principal conforme o modo do servidor,
+// registrando no mux os endpoints auxiliares do modo escolhido
+func buildMainHandler(ctx context.Context, cmd *cobra.Command, mux *http.ServeMux) (http.Handler, error) {
+	if serveDir == "" && (serveList || serveSPA || liveReload) {
+		return nil, fmt.Errorf("--listing, --spa e --live-reload requerem --dir")
+	}
+	if countTrue(serveDir != "", serveRoutes != "", serveInspect, len(proxyRoutes) > 0, recordUpstream != "", replayDir != "", uploadDir != "") > 1 {
+		return nil, fmt.Errorf("use apenas um modo entre --dir, --routes, --inspect, --proxy, --record, --replay e --upload-dir")
+	}
+	if cassetteDir != "" && recordUpstream == "" {
+		return nil, fmt.Errorf("--cassette requer --record")
+	}
+
+	switch {
+	case serveDir != "":
+		return buildStaticHandler(ctx, cmd, mux)
+	case serveRoutes != "":
+		return buildMockHandler(ctx, cmd, mux)
+	case serveInspect:
+		return buildInspectHandler(cmd, mux), nil
+	case len(proxyRoutes) > 0:
+		return buildProxyHandler(ctx, cmd, mux)
+	case recordUpstream != "":
+		return buildRecordHandler()
+	case replayDir != "":
+		return buildReplayHandler()
+	case uploadDir != "":
+		return buildUploadHandler()
+	default:
+		return http.HandlerFunc(handler), nil
+	}
+}
+
+// buildStaticHandler cria o handler do modo --dir, com live reload opcional
+func buildStaticHandler(ctx context.Context, cmd *cobra.Command, mux *http.ServeMux) (http.Handler, error) {
+	static, err := server.NewStaticHandler(serveDir, server.StaticOptions{
+		Listing: serveList,
+		SPA:     serveSPA,
+	})
+	if err != nil {
+		return nil, err
 	}
+	appLog.Infof("Servindo arquivos de %s", static.Root())
+	verbosePrint(cmd, "Listagem de diretórios: %v\n", serveList)
+	verbosePrint(cmd, "Modo SPA: %v\n", serveSPA)
+	if liveReload {
+		return withLiveReload(ctx, cmd, mux, static)
+	}
+	return static, nil
+}
+
+// buildMockHandler cria o handler do modo --routes, com recarga automática e /__routes
+func buildMockHandler(ctx context.Context, cmd *cobra.Command, mux *http.ServeMux) (http.Handler, error) {
+	mock, err := server.NewMockHandler(serveRoutes)
+	if err != nil {
+		return nil, err
+	}
+	mock.OnReload = func(routes int, err error) {
+		if err != nil {
+			appLog.Errorf("Erro ao recarregar %s (rotas anteriores mantidas): %v", mock.Path(), err)
+			return
+		}
+		appLog.Infof("Rotas recarregadas de %s: %d rota(s)", mock.Path(), routes)
+	}
+	if err := mock.Watch(ctx.Done()); err != nil {
+		appLog.Warnf("Recarga automática desabilitada: %v", err)
+	}
+	mux.Handle("/__routes", mock.RoutesHandler())
+
+	appLog.Infof("Servidor mock com %d rota(s) de %s", len(mock.Routes()), mock.Path())
+	for _, route := range mock.Routes() {
+		verbosePrint(cmd, "Rota: %s %s -> %d\n", route.Method, route.Path, route.Status)
+	}
+	return mock, nil
+}
+
+// buildInspectHandler cria o handler do modo --inspect, exibindo cada requisição no terminal
+func buildInspectHandler(cmd *cobra.Command, mux *http.ServeMux) http.Handler {
+	inspector := server.NewInspector(inspectSize, server.DefaultInspectMaxBody)
+	inspector.OnCapture = func(req server.CapturedRequest) {
+		target := req.Path
+		if len(req.Query) > 0 {
+			target += "?" + url.Values(req.Query).Encode()
+		}
+		appLog.Infof("#%d %s %s de %s (%d bytes)", req.ID, req.Method, target, req.RemoteAddr, req.BodySize)
+		for name, values := range req.Headers {
+			verbosePrint(cmd, "    %s: %s\n", name, strings.Join(values, ", "))
+		}
+		if req.Body != "" {
+			verbosePrint(cmd, "    %s\n", req.Body)
+		}
+	}
+	inspector.RegisterAdmin(mux)
+
+	appLog.Infof("Modo inspetor: guardando as últimas %d requisições (página em /__inspect, API em /__requests)", inspectSize)
+	return inspector
+}
+
+// countTrue conta quantas condições são verdadeiras
+func countTrue(conds ...bool) int {
+	n := 0
+	for _, c := range conds {
+		if c {
+			n++
+		}
+	}
+	return n
+}
+
+// printShutdownReport exibe o resumo do encerramento gracioso
+func printShutdownReport(report *server.ShutdownReport, grace time.Duration) {
+	if report.DeadlineExceeded {
+		appLog.Warnf("Prazo de %v atingido: %d requisição(ões) drenada(s), %d interrompida(s)",
+			grace, report.Drained, report.Remaining)
+	} else {
+		appLog.Infof("%d requisição(ões) drenada(s) em %v, prazo não atingido",
+			report.Drained, report.Duration.Round(time.Millisecond))
+	}
+	appLog.Infof("Servidor encerrado")
 }
 
 func handler(w http.ResponseWriter, r *http.Request) {
@@ -80,3 +493,20 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprint(w, "OK")
 }
+
+// registerHealthChecks registra /health/live e /health/ready com as verificações
+// declaradas em server.health.checks
+func registerHealthChecks(cmd *cobra.Command, mux *http.ServeMux) error {
+	checks := config.Get().Server.Health.Checks
+	health, err := server.NewHealth(checks)
+	if err != nil {
+		return fmt.Errorf("erro em server.health.checks: %w", err)
+	}
+	mux.Handle("GET /health/live", health.LiveHandler())
+	mux.Handle("GET /health/ready", health.ReadyHandler())
+
+	for _, c := range checks {
+		verbosePrint(cmd, "Verificação de saúde: %s (%s)\n", c.Name, c.Type)
+	}
+	return nil
+}
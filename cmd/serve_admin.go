@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+)
+
+var (
+	metricsPath string
+	adminPort   int
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&metricsPath, "metrics-path", "/metrics", "Caminho das métricas Prometheus (vazio desabilita)")
+	serveCmd.Flags().IntVar(&adminPort, "admin-port", 0,
+		"Porta separada para os endpoints administrativos, como as métricas (padrão: a porta principal)")
+}
+
+// newAdminMux retorna o mux dos endpoints administrativos: o próprio mux principal
+// ou, com --admin-port, um mux servido apenas na porta administrativa
+func newAdminMux(mux *http.ServeMux) (*http.ServeMux, error) {
+	if adminPort == 0 {
+		return mux, nil
+	}
+	if adminPort < constants.MinPort || adminPort > constants.MaxPort {
+		return nil, fmt.Errorf("--admin-port: "+constants.ErrInvalidPort, constants.MinPort, constants.MaxPort)
+	}
+	return http.NewServeMux(), nil
+}
+
+// registerMetrics expõe as métricas no mux administrativo e retorna o middleware
+// que as coleta; retorna nil se as métricas estiverem desabilitadas
+func registerMetrics(adminMux *http.ServeMux) (*server.Metrics, server.Middleware) {
+	if metricsPath == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(metricsPath, "/") {
+		metricsPath = "/" + metricsPath
+	}
+	metrics := server.NewMetrics()
+	adminMux.Handle("GET "+metricsPath, metrics.Handler())
+	return metrics, metrics.Middleware()
+}
+
+// startAdminServer inicia o listener da porta administrativa, se configurada
+func startAdminServer(ctx context.Context, host string, handler http.Handler, grace time.Duration) (*sync.WaitGroup, error) {
+	var wg sync.WaitGroup
+	if adminPort == 0 {
+		return &wg, nil
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(adminPort))
+	ln, err := net.Listen(constants.TCPProtocol, addr)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar porta administrativa: %w", err)
+	}
+	admin := server.New(&http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := admin.Serve(ctx, ln, grace); err != nil {
+			appLog.Errorf("Erro na porta administrativa: %v", err)
+		}
+	}()
+	appLog.Infof("Endpoints administrativos em http://%s", addr)
+	return &wg, nil
+}
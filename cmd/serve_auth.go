@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	authTokens []string
+	authAllow  []string
+	authDeny   []string
+)
+
+var hashPasswordCmd = &cobra.Command{
+	Use:   "hash-password [senha]",
+	Short: "Gera o hash bcrypt de uma senha para server.auth.users",
+	Long: `Gera o hash bcrypt de uma senha para uso em server.auth.users.
+Sem argumento, a senha é lida da entrada padrão (evita que fique no histórico do shell).
+
+Exemplos:
+  bast serve hash-password          # Lê a senha da entrada padrão
+  echo -n segredo | bast serve hash-password`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var password string
+		if len(args) == 1 {
+			password = args[0]
+		} else {
+			fmt.Fprint(os.Stderr, "Senha: ")
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("erro ao ler senha: %w", err)
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+		if password == "" {
+			return fmt.Errorf("senha vazia")
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("erro ao gerar hash: %w", err)
+		}
+		fmt.Println(string(hash))
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.AddCommand(hashPasswordCmd)
+
+	serveCmd.Flags().StringArrayVar(&authTokens, "auth-token", nil, "Token aceito em Authorization: Bearer (repetível; soma-se a server.auth.tokens)")
+	serveCmd.Flags().StringArrayVar(&authAllow, "allow", nil, "CIDR permitido (repetível; soma-se a server.auth.allow)")
+	serveCmd.Flags().StringArrayVar(&authDeny, "deny", nil, "CIDR bloqueado (repetível; soma-se a server.auth.deny)")
+}
+
+// newAuthMiddleware cria o middleware de autenticação a partir de server.auth e das
+// flags; retorna nil se nenhuma restrição estiver configurada
+func newAuthMiddleware(cmd *cobra.Command) (server.Middleware, error) {
+	cfg := config.Get().Server.Auth
+	cfg.Tokens = append(append([]string(nil), cfg.Tokens...), authTokens...)
+	cfg.Allow = append(append([]string(nil), cfg.Allow...), authAllow...)
+	cfg.Deny = append(append([]string(nil), cfg.Deny...), authDeny...)
+
+	auth, err := server.NewAuth(cfg, appLog)
+	if err != nil {
+		return nil, fmt.Errorf("erro em server.auth: %w", err)
+	}
+	if auth == nil {
+		return nil, nil
+	}
+
+	appLog.Infof("Autenticação habilitada: %d usuário(s), %d token(s), %d CIDR(s) permitido(s), %d bloqueado(s)",
+		len(cfg.Users), len(cfg.Tokens), len(cfg.Allow), len(cfg.Deny))
+	verbosePrint(cmd, "Caminhos isentos de autenticação: %v\n", cfg.Exempt)
+	return auth.Middleware(), nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+)
+
+var (
+	recordUpstream string
+	cassetteDir    string
+	replayDir      string
+	cassetteMatch  []string
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&recordUpstream, "record", "", "Repassa as requisições ao upstream informado (URL ou upstream=URL) e grava cada par requisição/resposta em --cassette")
+	serveCmd.Flags().StringVar(&cassetteDir, "cassette", "", "Diretório onde --record grava as interações")
+	serveCmd.Flags().StringVar(&replayDir, "replay", "", "Responde a partir das interações gravadas no diretório, sem acessar o upstream")
+	serveCmd.Flags().StringSliceVar(&cassetteMatch, "match", server.DefaultMatch,
+		"Critérios de correspondência do --replay: method, path, query, body e header:Nome")
+}
+
+// buildRecordHandler cria o handler do modo --record
+func buildRecordHandler() (http.Handler, error) {
+	if cassetteDir == "" {
+		return nil, fmt.Errorf("--record requer --cassette")
+	}
+	// Aceita também a forma upstream=URL
+	upstream := strings.TrimPrefix(recordUpstream, "upstream=")
+	rec, err := server.NewRecorder(upstream, cassetteDir)
+	if err != nil {
+		return nil, err
+	}
+	rec.OnRecord = func(file string, in *server.Interaction, err error) {
+		if err != nil {
+			appLog.Errorf("Erro ao gravar %s %s: %v", in.Request.Method, in.Request.Path, err)
+			return
+		}
+		appLog.Debugf("Gravado %s %s -> %d em %s", in.Request.Method, in.Request.Path, in.Response.Status, filepath.Base(file))
+	}
+
+	appLog.Infof("Gravando interações com %s em %s", rec.Upstream(), cassetteDir)
+	return rec, nil
+}
+
+// buildReplayHandler cria o handler do modo --replay
+func buildReplayHandler() (http.Handler, error) {
+	match, err := server.ParseMatch(cassetteMatch)
+	if err != nil {
+		return nil, err
+	}
+	replayer, err := server.LoadCassette(replayDir, match)
+	if err != nil {
+		return nil, err
+	}
+
+	appLog.Infof("Reproduzindo %d interação(ões) de %s (correspondência por %v)", replayer.Len(), replayDir, cassetteMatch)
+	return replayer, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chaosEnabled      bool
+	chaosLatency      string
+	chaosErrorRate    float64
+	chaosErrorCodes   []int
+	chaosResetRate    float64
+	chaosTruncateRate float64
+	chaosSeed         int64
+)
+
+func init() {
+	serveCmd.Flags().BoolVar(&chaosEnabled, "chaos", false, "Habilita a injeção de falhas (padrão: server.chaos.enabled)")
+	serveCmd.Flags().StringVar(&chaosLatency, "chaos-latency", "",
+		"Latência injetada: 100ms, uniform:50ms,200ms ou normal:100ms,20ms (habilita a injeção de falhas)")
+	serveCmd.Flags().Float64Var(&chaosErrorRate, "chaos-error-rate", 0, "Fração (0-1) de respostas com erro (habilita a injeção de falhas)")
+	serveCmd.Flags().IntSliceVar(&chaosErrorCodes, "chaos-error-codes", nil, "Status de erro sorteados (padrão: 500,502,503)")
+	serveCmd.Flags().Float64Var(&chaosResetRate, "chaos-reset-rate", 0, "Fração (0-1) de conexões reiniciadas (habilita a injeção de falhas)")
+	serveCmd.Flags().Float64Var(&chaosTruncateRate, "chaos-truncate-rate", 0, "Fração (0-1) de corpos truncados (habilita a injeção de falhas)")
+	serveCmd.Flags().Int64Var(&chaosSeed, "seed", 0, "Semente dos sorteios da injeção de falhas, para resultados reproduzíveis")
+}
+
+// newChaos cria o injetor de falhas a partir de server.chaos e das flags, que
+// alteram a regra global, e expõe /__chaos no mux administrativo. O injetor é
+// criado mesmo desabilitado, para que possa ser ligado em tempo de execução
+// com PUT /__chaos
+func newChaos(cmd *cobra.Command, adminMux *http.ServeMux) (*server.Chaos, error) {
+	cfg := config.Get().Server.Chaos
+	flags := cmd.Flags()
+
+	if flags.Changed("chaos-latency") {
+		cfg.Global.Latency = chaosLatency
+		cfg.Enabled = true
+	}
+	if flags.Changed("chaos-error-rate") {
+		cfg.Global.ErrorRate = chaosErrorRate
+		cfg.Enabled = true
+	}
+	if flags.Changed("chaos-error-codes") {
+		cfg.Global.ErrorCodes = chaosErrorCodes
+	}
+	if flags.Changed("chaos-reset-rate") {
+		cfg.Global.ResetRate = chaosResetRate
+		cfg.Enabled = true
+	}
+	if flags.Changed("chaos-truncate-rate") {
+		cfg.Global.TruncateRate = chaosTruncateRate
+		cfg.Enabled = true
+	}
+	if flags.Changed("seed") {
+		cfg.Seed = chaosSeed
+	}
+	if flags.Changed("chaos") {
+		cfg.Enabled = chaosEnabled
+	}
+
+	chaos, err := server.NewChaos(cfg)
+	if err != nil {
+		return nil, err
+	}
+	adminMux.Handle("/__chaos", chaos.Handler())
+	if !cfg.Enabled {
+		return chaos, nil
+	}
+	appLog.Warnf("Injeção de falhas habilitada (configuração em /__chaos)")
+	if cfg.Seed != 0 {
+		appLog.Infof("Semente da injeção de falhas: %d", cfg.Seed)
+	}
+	return chaos, nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosEnabledAtRuntime(t *testing.T) {
+	config.Reset()
+	config.Init("")
+
+	adminMux := http.NewServeMux()
+	chaos, err := newChaos(serveCmd, adminMux)
+	require.NoError(t, err)
+	require.NotNil(t, chaos)
+	assert.False(t, chaos.Config().Enabled)
+
+	h := server.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), chaos.Middleware())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Desabilitada na partida, a injeção de falhas é ligada pelo /__chaos
+	rec = httptest.NewRecorder()
+	adminMux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/__chaos",
+		strings.NewReader(`{"enabled": true, "global": {"error_rate": 1, "error_codes": [503]}}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, chaos.Config().Enabled)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,513 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/daemon"
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonInstance string
+	startWait      time.Duration
+	stopTimeout    time.Duration
+	stopAll        bool
+	logsFollow     bool
+	logsLines      int
+)
+
+// logsPollInterval intervalo entre as leituras do log com --follow
+const logsPollInterval = 250 * time.Millisecond
+
+// startFailureLines linhas do log exibidas quando a instância falha ao iniciar
+const startFailureLines = 20
+
+var serveStartCmd = &cobra.Command{
+	Use:   "start [nome] [-- flags do serve]",
+	Short: "Inicia o servidor em segundo plano",
+	Long: `Inicia o servidor em segundo plano, desvinculado do terminal.
+As flags do serve vêm depois de '--'. O PID, o estado e o log da instância ficam
+em ~/.bast/run/<nome>.{pid,json,log}; sem nome, a instância se chama "default".
+Várias instâncias com nomes diferentes podem rodar ao mesmo tempo.
+
+Exemplos:
+  bast serve start                          # Instância "default" na porta padrão
+  bast serve start docs -- --dir ./docs -p 3000
+  bast serve start api -- --routes mock.yaml -p 4000`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if dash := cmd.ArgsLenAtDash(); dash > 1 || (dash < 0 && len(args) > 1) {
+			return fmt.Errorf("informe no máximo um nome antes de '--' (as flags do serve vêm depois de '--')")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, serveArgs := daemon.DefaultName, args
+		if dash := cmd.ArgsLenAtDash(); dash != 0 && len(args) > 0 {
+			name, serveArgs = args[0], args[1:]
+		}
+		return startDaemon(cmd, name, serveArgs)
+	},
+}
+
+var serveStopCmd = &cobra.Command{
+	Use:   "stop [nome]",
+	Short: "Encerra uma instância em segundo plano",
+	Long: `Envia SIGTERM à instância e aguarda o encerramento gracioso; após --timeout,
+o processo é encerrado com SIGKILL.
+
+Exemplos:
+  bast serve stop              # Encerra a instância "default"
+  bast serve stop docs --timeout 30s
+  bast serve stop --all`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if stopAll && len(args) > 0 {
+			return fmt.Errorf("use um nome ou --all, não ambos")
+		}
+		store, err := daemonStore()
+		if err != nil {
+			return err
+		}
+		if !stopAll {
+			return stopDaemon(store, instanceName(args))
+		}
+		names, err := store.Names()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Println("Nenhuma instância em execução.")
+			return nil
+		}
+		var errs []error
+		for _, name := range names {
+			if err := stopDaemon(store, name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	},
+}
+
+var serveStatusCmd = &cobra.Command{
+	Use:   "status [nome]",
+	Short: "Mostra as instâncias em segundo plano",
+	Long: `Mostra PID, endereço, tempo de execução e saúde (conexão TCP na porta) das
+instâncias em segundo plano. Com um nome, mostra apenas essa instância e termina
+com erro se ela não estiver em execução.
+
+Exemplos:
+  bast serve status
+  bast serve status docs`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := daemonStore()
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			return printDaemonStatus(store, args[0])
+		}
+		names, err := store.Names()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Println("Nenhuma instância em execução.")
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NOME\tPID\tENDEREÇO\tUPTIME\tSAÚDE")
+		for _, name := range names {
+			st := readDaemonStatus(store, name)
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, st.pid(), st.address(), st.uptime(), st.health)
+		}
+		return tw.Flush()
+	},
+}
+
+var serveLogsCmd = &cobra.Command{
+	Use:   "logs [nome]",
+	Short: "Mostra o log de uma instância em segundo plano",
+	Long: `Mostra as últimas linhas do log da instância; com --follow, continua
+exibindo as novas linhas até Ctrl+C.
+
+Exemplos:
+  bast serve logs
+  bast serve logs docs -n 100
+  bast serve logs docs -f`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := daemonStore()
+		if err != nil {
+			return err
+		}
+		name := instanceName(args)
+		if err := daemon.ValidateName(name); err != nil {
+			return err
+		}
+		path := store.LogFile(name)
+		lines, err := daemon.Tail(path, logsLines)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("instância '%s' sem log em %s", name, store.Dir())
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler log: %w", err)
+		}
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		if !logsFollow {
+			return nil
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return followLog(ctx, path, os.Stdout)
+	},
+}
+
+func init() {
+	serveCmd.AddCommand(serveStartCmd, serveStopCmd, serveStatusCmd, serveLogsCmd)
+
+	// Definida pelo serve start no processo filho, que publica seu estado ao escutar
+	serveCmd.Flags().StringVar(&daemonInstance, "daemon-instance", "", "Nome da instância em segundo plano")
+	_ = serveCmd.Flags().MarkHidden("daemon-instance")
+
+	serveStartCmd.Flags().DurationVar(&startWait, "wait", 10*time.Second, "Tempo máximo de espera até o servidor começar a escutar")
+	serveStopCmd.Flags().DurationVar(&stopTimeout, "timeout", 10*time.Second, "Tempo de espera após o SIGTERM antes do SIGKILL")
+	serveStopCmd.Flags().BoolVar(&stopAll, "all", false, "Encerra todas as instâncias")
+	serveLogsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Continua exibindo as novas linhas do log")
+	serveLogsCmd.Flags().IntVarP(&logsLines, "lines", "n", 50, "Quantidade de linhas finais exibidas")
+}
+
+// daemonStore abre o diretório das instâncias em ~/.bast/run
+func daemonStore() (*daemon.Store, error) {
+	dir, err := daemon.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return daemon.NewStore(dir), nil
+}
+
+// instanceName retorna o nome informado ou o nome padrão
+func instanceName(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return daemon.DefaultName
+}
+
+// startDaemon inicia "bast serve" em segundo plano e aguarda a instância publicar
+// seu endereço
+func startDaemon(cmd *cobra.Command, name string, serveArgs []string) error {
+	if err := daemon.ValidateName(name); err != nil {
+		return err
+	}
+	store, err := daemonStore()
+	if err != nil {
+		return err
+	}
+	switch pid, _, err := store.Lookup(name); {
+	case err == nil:
+		return fmt.Errorf("instância '%s' já está em execução (PID %d); use 'bast serve stop %s'", name, pid, name)
+	case errors.Is(err, daemon.ErrStale):
+		verbosePrint(cmd, "Removendo arquivo de PID obsoleto da instância '%s' (PID %d)\n", name, pid)
+	}
+	if err := store.Remove(name); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("erro ao localizar o executável: %w", err)
+	}
+	childArgs := []string{"serve", "--daemon-instance", name}
+	if cmd.Flags().Changed("config") {
+		childArgs = append(childArgs, "--config", cfgFile)
+	}
+	if isVerbose(cmd) {
+		childArgs = append(childArgs, "--verbose")
+	}
+	childArgs = append(childArgs, serveArgs...)
+
+	logFile, err := store.OpenLog(name)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	fmt.Fprintf(logFile, "=== %s: bast serve %s ===\n", time.Now().Format(time.DateTime), strings.Join(serveArgs, " "))
+
+	verbosePrint(cmd, "Executando %s %s\n", exe, strings.Join(childArgs, " "))
+	child, err := daemon.Start(exe, childArgs, logFile)
+	if err != nil {
+		return err
+	}
+	pid := child.Process.Pid
+	if err := store.WritePID(name, pid); err != nil {
+		_ = child.Process.Kill()
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+	deadline := time.After(startWait)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-exited:
+			_ = store.Remove(name)
+			if lines, _ := daemon.Tail(store.LogFile(name), startFailureLines); len(lines) > 0 {
+				fmt.Fprintln(os.Stderr, strings.Join(lines, "\n"))
+			}
+			return fmt.Errorf("o servidor encerrou durante a inicialização (%v); log em %s", err, store.LogFile(name))
+		case <-deadline:
+			return fmt.Errorf("o servidor não começou a escutar em %v (PID %d continua em execução); log em %s",
+				startWait, pid, store.LogFile(name))
+		case <-ticker.C:
+			inst, err := store.ReadState(name)
+			if err != nil || inst.PID != pid {
+				continue
+			}
+			fmt.Printf("Instância '%s' iniciada em segundo plano\n", name)
+			fmt.Printf("   PID:      %d\n", pid)
+			fmt.Printf("   Endereço: %s\n", inst.Address)
+			fmt.Printf("   Log:      %s\n", store.LogFile(name))
+			return nil
+		}
+	}
+}
+
+// stopDaemon encerra a instância e remove seus arquivos de PID e estado
+func stopDaemon(store *daemon.Store, name string) error {
+	if err := daemon.ValidateName(name); err != nil {
+		return err
+	}
+	// O PID só é sinalizado se ainda pertencer à instância: depois de uma queda ou
+	// reinício, o sistema pode tê-lo reutilizado para outro processo
+	pid, _, err := store.Lookup(name)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		return fmt.Errorf("instância '%s' não está em execução", name)
+	}
+	if errors.Is(err, daemon.ErrStale) {
+		fmt.Printf("Instância '%s' não estava em execução (PID %d obsoleto); arquivos removidos\n", name, pid)
+		return store.Remove(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	killed, err := daemon.Stop(pid, stopTimeout)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		fmt.Printf("Instância '%s' não estava em execução (PID %d); arquivos obsoletos removidos\n", name, pid)
+		return store.Remove(name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := store.Remove(name); err != nil {
+		return err
+	}
+	if killed {
+		fmt.Printf("Instância '%s' (PID %d) não encerrou em %v e foi finalizada com SIGKILL\n", name, pid, stopTimeout)
+		return nil
+	}
+	fmt.Printf("Instância '%s' (PID %d) encerrada em %v\n", name, pid, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// daemonStatus situação de uma instância para o status
+type daemonStatus struct {
+	PID    int
+	Alive  bool
+	Inst   *daemon.Instance
+	health string
+}
+
+func (s daemonStatus) pid() string {
+	if s.PID == 0 {
+		return "-"
+	}
+	return fmt.Sprint(s.PID)
+}
+
+func (s daemonStatus) address() string {
+	if s.Inst == nil {
+		return "-"
+	}
+	return s.Inst.Address
+}
+
+func (s daemonStatus) uptime() string {
+	if s.Inst == nil || !s.Alive {
+		return "-"
+	}
+	return time.Since(s.Inst.Started).Round(time.Second).String()
+}
+
+// readDaemonStatus lê o PID e o estado da instância, confirma que o processo é
+// dela e verifica a porta
+func readDaemonStatus(store *daemon.Store, name string) daemonStatus {
+	var st daemonStatus
+	pid, inst, err := store.Lookup(name)
+	if err != nil && !errors.Is(err, daemon.ErrStale) {
+		st.health = "parada"
+		return st
+	}
+	st.PID = pid
+	st.Alive = err == nil
+	st.Inst = inst
+	switch {
+	case !st.Alive:
+		st.health = "parada (PID obsoleto)"
+	case st.Inst == nil:
+		st.health = "iniciando"
+	default:
+		st.health = probeInstance(st.Inst.Address)
+	}
+	return st
+}
+
+// probeInstance verifica se o endereço da instância aceita conexões TCP
+func probeInstance(address string) string {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "endereço inválido"
+	}
+	host, port := u.Hostname(), u.Port()
+	// Servidores em todas as interfaces são verificados pela interface local
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+		if ip.To4() == nil {
+			host = "::1"
+		}
+	}
+	result, err := portcheck.ProbeAddress(context.Background(), net.JoinHostPort(host, port), 2*time.Second)
+	if err != nil {
+		return "endereço inválido"
+	}
+	if !result.Open {
+		return "sem resposta"
+	}
+	return fmt.Sprintf("ok (%v)", result.Duration.Round(100*time.Microsecond))
+}
+
+// printDaemonStatus mostra os detalhes de uma instância; retorna erro se ela não
+// estiver em execução
+func printDaemonStatus(store *daemon.Store, name string) error {
+	if err := daemon.ValidateName(name); err != nil {
+		return err
+	}
+	st := readDaemonStatus(store, name)
+	fmt.Printf("Instância: %s\n", name)
+	fmt.Printf("   Estado:   %s\n", st.health)
+	if st.PID == 0 || !st.Alive {
+		return fmt.Errorf("instância '%s' não está em execução", name)
+	}
+	fmt.Printf("   PID:      %d\n", st.PID)
+	if st.Inst != nil {
+		fmt.Printf("   Endereço: %s\n", st.Inst.Address)
+		fmt.Printf("   Início:   %s\n", st.Inst.Started.Format(time.DateTime))
+		fmt.Printf("   Uptime:   %s\n", st.uptime())
+		fmt.Printf("   Comando:  bast %s\n", strings.Join(st.Inst.Args, " "))
+	}
+	fmt.Printf("   Log:      %s\n", store.LogFile(name))
+	return nil
+}
+
+// followLog exibe o que for acrescentado ao log até o contexto ser cancelado
+func followLog(ctx context.Context, path string, out io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(logsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() < offset {
+			// Log truncado: recomeça do início
+			offset = 0
+		}
+		if info.Size() == offset {
+			continue
+		}
+		n, err := io.Copy(out, io.NewSectionReader(f, offset, info.Size()-offset))
+		offset += n
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// prepareDaemonLogging desativa as cores do log quando o servidor roda como
+// processo de serve start, cuja saída vai para o arquivo de log
+func prepareDaemonLogging() {
+	if daemonInstance == "" {
+		return
+	}
+	if f, ok := appLog.Formatter.(*logrus.TextFormatter); ok {
+		f.ForceColors = false
+		f.DisableColors = true
+	}
+}
+
+// publishDaemonInstance publica o estado da instância quando o servidor roda como
+// processo de serve start; a função retornada remove os arquivos ao encerrar
+func publishDaemonInstance(scheme, host string, addr net.Addr) (func(), error) {
+	if daemonInstance == "" {
+		return func() {}, nil
+	}
+	store, err := daemonStore()
+	if err != nil {
+		return nil, err
+	}
+	pid := os.Getpid()
+	processStart, _ := daemon.ProcessStart(pid)
+	inst := daemon.Instance{
+		Name:         daemonInstance,
+		PID:          pid,
+		Address:      scheme + "://" + net.JoinHostPort(host, strconv.Itoa(addr.(*net.TCPAddr).Port)),
+		Started:      time.Now(),
+		Args:         os.Args[1:],
+		ProcessStart: processStart,
+	}
+	if err := store.WriteState(inst); err != nil {
+		return nil, err
+	}
+	return func() {
+		// Remove os arquivos apenas se ainda pertencem a este processo
+		if current, err := store.ReadPID(daemonInstance); err == nil && current == pid {
+			_ = store.Remove(daemonInstance)
+		}
+	}, nil
+}
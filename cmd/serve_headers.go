@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	corsEnabled     bool
+	corsOrigins     []string
+	compressEnabled bool
+	securityHeaders bool
+)
+
+func init() {
+	serveCmd.Flags().BoolVar(&corsEnabled, "cors", false, "Habilita CORS (padrão: server.cors.enabled)")
+	serveCmd.Flags().StringArrayVar(&corsOrigins, "cors-origin", nil,
+		"Origem permitida pelo CORS (repetível; substitui server.cors.origins e habilita CORS)")
+	serveCmd.Flags().BoolVar(&compressEnabled, "compress", false, "Comprime respostas com br/gzip/deflate (padrão: server.compression.enabled)")
+	serveCmd.Flags().BoolVar(&securityHeaders, "security-headers", false,
+		"Adiciona HSTS, CSP e demais cabeçalhos de segurança (padrão: server.security_headers.enabled)")
+}
+
+// newHeaderMiddlewares cria os middlewares de cabeçalhos de segurança, CORS e
+// compressão, nessa ordem, a partir de server.* e das flags
+func newHeaderMiddlewares(cmd *cobra.Command) ([]server.Middleware, error) {
+	cfg := config.Get().Server
+
+	if cmd.Flags().Changed("cors") {
+		cfg.CORS.Enabled = corsEnabled
+	}
+	if len(corsOrigins) > 0 {
+		cfg.CORS.Enabled = true
+		cfg.CORS.Origins = corsOrigins
+	}
+	if cmd.Flags().Changed("compress") {
+		cfg.Compression.Enabled = compressEnabled
+	}
+	if cmd.Flags().Changed("security-headers") {
+		cfg.Security.Enabled = securityHeaders
+	}
+
+	compress, err := server.Compress(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	cors, err := server.CORS(cfg.CORS)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CORS.Enabled {
+		appLog.Infof("CORS habilitado para %v", cfg.CORS.Origins)
+	}
+	if cfg.Compression.Enabled {
+		appLog.Infof("Compressão habilitada (%v, a partir de %d bytes)", cfg.Compression.Algorithms, cfg.Compression.MinSize)
+	}
+	if cfg.Security.Enabled {
+		appLog.Infof("Cabeçalhos de segurança habilitados")
+	}
+	return []server.Middleware{server.SecurityHeaders(cfg.Security), cors, compress}, nil
+}
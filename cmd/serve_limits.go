@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rateLimit       float64
+	globalRateLimit float64
+	maxConns        int
+	maxBodySize     string
+)
+
+func init() {
+	serveCmd.Flags().Float64Var(&rateLimit, "rate-limit", 0, "Requisições por segundo por IP (padrão: server.limits.rate; 0 desabilita)")
+	serveCmd.Flags().Float64Var(&globalRateLimit, "global-rate-limit", 0,
+		"Requisições por segundo somando todos os clientes (padrão: server.limits.global_rate; 0 desabilita)")
+	serveCmd.Flags().IntVar(&maxConns, "max-conns", 0, "Máximo de conexões simultâneas (padrão: server.limits.max_connections; 0 desabilita)")
+	serveCmd.Flags().StringVar(&maxBodySize, "max-body-size", "", "Tamanho máximo do corpo das requisições, como 10MB (padrão: server.limits.max_body_size)")
+}
+
+// serveLimits limitadores configurados para o servidor principal
+type serveLimits struct {
+	rate        *server.RateLimiter
+	conns       *server.ConnLimiter
+	body        server.Middleware
+	maxBodySize int64
+}
+
+// newServeLimits cria os limitadores a partir de server.limits e das flags
+func newServeLimits(cmd *cobra.Command) (*serveLimits, error) {
+	cfg := config.Get().Server.Limits
+
+	if cmd.Flags().Changed("rate-limit") {
+		cfg.Rate = rateLimit
+	}
+	if cmd.Flags().Changed("global-rate-limit") {
+		cfg.GlobalRate = globalRateLimit
+	}
+	if cmd.Flags().Changed("max-conns") {
+		cfg.MaxConnections = maxConns
+	}
+	if cmd.Flags().Changed("max-body-size") {
+		cfg.MaxBodySize = maxBodySize
+	}
+	if cfg.MaxConnections < 0 {
+		return nil, fmt.Errorf("limite de conexões inválido: %d", cfg.MaxConnections)
+	}
+
+	rate, err := server.NewRateLimiter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	body, limit, err := server.MaxBodySize(cfg.MaxBodySize)
+	if err != nil {
+		return nil, fmt.Errorf("tamanho máximo do corpo inválido: %w", err)
+	}
+	limits := &serveLimits{rate: rate, conns: server.NewConnLimiter(cfg.MaxConnections), body: body, maxBodySize: limit}
+
+	if cfg.Rate > 0 {
+		appLog.Infof("Limite de taxa: %g req/s por IP", cfg.Rate)
+	}
+	if cfg.GlobalRate > 0 {
+		appLog.Infof("Limite de taxa global: %g req/s", cfg.GlobalRate)
+	}
+	if limits.conns != nil {
+		appLog.Infof("Limite de conexões simultâneas: %d", cfg.MaxConnections)
+	}
+	if limit > 0 {
+		appLog.Infof("Tamanho máximo do corpo: %s", cfg.MaxBodySize)
+	}
+	return limits, nil
+}
+
+// enabled indica se algum limitador está ativo
+func (l *serveLimits) enabled() bool {
+	return l.rate != nil || l.conns != nil || l.maxBodySize > 0
+}
+
+// register expõe o estado dos limitadores em /__limits e nas métricas
+func (l *serveLimits) register(adminMux *http.ServeMux, metrics *server.Metrics) {
+	if !l.enabled() {
+		return
+	}
+	adminMux.Handle("GET /__limits", server.LimitsHandler(l.rate, l.conns, l.maxBodySize))
+	if metrics != nil {
+		metrics.AddCollector(server.LimitsCollector(l.rate, l.conns))
+	}
+}
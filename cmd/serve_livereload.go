@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	liveReload       bool
+	liveReloadIgnore []string
+	liveReloadCSS    bool
+)
+
+func init() {
+	serveCmd.Flags().BoolVar(&liveReload, "live-reload", false, "Recarrega as páginas abertas quando arquivos do --dir mudam")
+	serveCmd.Flags().StringSliceVar(&liveReloadIgnore, "live-reload-ignore", server.DefaultWatchIgnore,
+		"Padrões de arquivos e diretórios ignorados pelo --live-reload (glob por segmento do caminho)")
+	serveCmd.Flags().BoolVar(&liveReloadCSS, "css-hot-swap", true, "Troca apenas as folhas de estilo quando só arquivos .css mudam, sem recarregar a página")
+}
+
+// withLiveReload observa a raiz do --dir e injeta o script de recarga nas páginas
+// HTML servidas; o observador é encerrado quando o contexto é cancelado
+func withLiveReload(ctx context.Context, cmd *cobra.Command, mux *http.ServeMux, static *server.StaticHandler) (http.Handler, error) {
+	reload, err := server.NewLiveReload(static.Root(), liveReloadIgnore, liveReloadCSS)
+	if err != nil {
+		return nil, err
+	}
+	reload.OnChange = func(files []string, event string) {
+		if event == "css" {
+			appLog.Infof("Folhas de estilo alteradas (%s): atualizando %d página(s)", strings.Join(files, ", "), reload.Clients())
+			return
+		}
+		appLog.Infof("Arquivos alterados (%s): recarregando %d página(s)", strings.Join(files, ", "), reload.Clients())
+	}
+	if err := reload.Watch(ctx.Done()); err != nil {
+		return nil, fmt.Errorf("live reload: %w", err)
+	}
+	reload.Register(mux)
+
+	appLog.Infof("Live reload ativo (eventos em %s)", server.LiveReloadEventsPath)
+	verbosePrint(cmd, "Ignorados pelo live reload: %s\n", strings.Join(liveReloadIgnore, ", "))
+	verbosePrint(cmd, "Hot swap de CSS: %v\n", liveReloadCSS)
+	return reload.Inject(static), nil
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
+)
+
+// portAuto valor de --port que escolhe a primeira porta livre
+const portAuto = "auto"
+
+// portFallbackAttempts portas testadas acima da inicial quando não há --port-range
+const portFallbackAttempts = 100
+
+var (
+	portFallback bool
+	portRange    string
+	portFile     string
+	portJSON     bool
+)
+
+func init() {
+	serveCmd.Flags().BoolVar(&portFallback, "port-fallback", false, "Usa a próxima porta livre se a porta pedida estiver ocupada")
+	serveCmd.Flags().StringVar(&portRange, "port-range", "", "Faixa testada por --port auto e --port-fallback, ex.: 8000-8100 (padrão: até 100 portas acima da inicial)")
+	serveCmd.Flags().StringVar(&portFile, "port-file", "", "Grava a porta escolhida no arquivo (removido ao encerrar)")
+	serveCmd.Flags().BoolVar(&portJSON, "port-json", false, "Imprime o endereço escolhido em uma linha JSON na saída padrão (logs vão para a saída de erro)")
+}
+
+// listenServe abre o listener do servidor. Com --port auto ou --port-fallback,
+// testa as portas candidatas com bind e mantém o listener da primeira livre;
+// settings.Port passa a conter a porta escolhida
+func listenServe(settings *serveSettings) (net.Listener, error) {
+	requested := settings.Port.Value
+	auto := requested == portAuto
+	if !auto && !portFallback {
+		if portRange != "" {
+			return nil, fmt.Errorf("--port-range requer --port auto ou --port-fallback")
+		}
+		ln, err := net.Listen(constants.TCPProtocol, net.JoinHostPort(settings.Host.Value, requested))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao executar servidor: %w", err)
+		}
+		return ln, nil
+	}
+
+	ports, err := candidatePorts(requested)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := portcheck.ListenFirst(settings.Host.Value, ports)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar servidor: %w", err)
+	}
+
+	chosen := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	switch {
+	case auto:
+		settings.Port = serveSetting{Value: chosen, Source: "automática"}
+		appLog.Infof("Porta %s escolhida automaticamente", chosen)
+	case chosen != requested:
+		appLog.Warnf("Porta %s em uso; usando a porta %s", requested, chosen)
+		settings.Port = serveSetting{Value: chosen, Source: settings.Port.Source + ", fallback"}
+	}
+	return ln, nil
+}
+
+// candidatePorts lista as portas testadas: a faixa de --port-range (depois da
+// porta pedida, no fallback) ou as portas a partir da inicial
+func candidatePorts(requested string) ([]int, error) {
+	var ports []int
+	start := 0
+	if requested == portAuto {
+		start = config.Get().Server.DefaultPort
+		if start == 0 {
+			start = constants.DefaultPort
+		}
+	} else {
+		start, _ = strconv.Atoi(requested)
+		ports = append(ports, start)
+	}
+
+	if portRange != "" {
+		from, to, err := portcheck.ParseRange(portRange)
+		if err != nil {
+			return nil, fmt.Errorf("--port-range: %w", err)
+		}
+		for p := from; p <= to; p++ {
+			if len(ports) == 0 || p != ports[0] {
+				ports = append(ports, p)
+			}
+		}
+		return ports, nil
+	}
+
+	if len(ports) > 0 {
+		start++
+	}
+	for p := start; p <= constants.MaxPort && p < start+portFallbackAttempts; p++ {
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// preparePortReport direciona os logs para a saída de erro quando --port-json
+// reserva a saída padrão para o endereço escolhido
+func preparePortReport() {
+	if portJSON {
+		appLog.SetOutput(os.Stderr)
+	}
+}
+
+// reportPort grava a porta escolhida em --port-file e a imprime com --port-json;
+// a função retornada remove o arquivo ao encerrar
+func reportPort(scheme, host string, addr net.Addr) (func(), error) {
+	port := addr.(*net.TCPAddr).Port
+	if portJSON {
+		data, _ := json.Marshal(map[string]interface{}{
+			"host": host,
+			"port": port,
+			"url":  scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port)),
+			"pid":  os.Getpid(),
+		})
+		fmt.Println(string(data))
+	}
+	if portFile == "" {
+		return func() {}, nil
+	}
+	if err := os.WriteFile(portFile, []byte(strconv.Itoa(port)+"\n"), constants.ConfigFilePerm); err != nil {
+		return nil, fmt.Errorf("erro ao gravar --port-file: %w", err)
+	}
+	return func() { _ = os.Remove(portFile) }, nil
+}
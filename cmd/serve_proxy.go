@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyRoutes         []string
+	proxyStrategy       string
+	proxyStripPrefix    bool
+	proxyHealthPath     string
+	proxyHealthInterval time.Duration
+	proxyRequestHeader  []string
+	proxyResponseHeader []string
+)
+
+func init() {
+	serveCmd.Flags().StringArrayVar(&proxyRoutes, "proxy", nil,
+		"Rota de proxy prefixo=destino[,destino...] com URLs ou um diretório (repetível)")
+	serveCmd.Flags().StringVar(&proxyStrategy, "proxy-strategy", server.ProxyRoundRobin,
+		"Balanceamento entre upstreams: round-robin ou failover")
+	serveCmd.Flags().BoolVar(&proxyStripPrefix, "proxy-strip-prefix", false, "Remove o prefixo da rota antes de repassar ao upstream")
+	serveCmd.Flags().StringVar(&proxyHealthPath, "proxy-health-path", "/health", "Caminho verificado em cada upstream (vazio desabilita)")
+	serveCmd.Flags().DurationVar(&proxyHealthInterval, "proxy-health-interval", 10*time.Second, "Intervalo entre verificações de saúde dos upstreams")
+	serveCmd.Flags().StringArrayVar(&proxyRequestHeader, "proxy-header", nil,
+		"Cabeçalho da requisição ao upstream: 'Nome: valor' define, '-Nome' remove (repetível)")
+	serveCmd.Flags().StringArrayVar(&proxyResponseHeader, "proxy-response-header", nil,
+		"Cabeçalho da resposta do upstream: 'Nome: valor' define, '-Nome' remove (repetível)")
+}
+
+// buildProxyHandler cria o handler do modo --proxy, com verificações de saúde e /__proxy
+func buildProxyHandler(ctx context.Context, cmd *cobra.Command, mux *http.ServeMux) (http.Handler, error) {
+	proxy, err := server.NewProxy(proxyRoutes, server.ProxyOptions{
+		Strategy:        proxyStrategy,
+		StripPrefix:     proxyStripPrefix,
+		HealthPath:      proxyHealthPath,
+		HealthInterval:  proxyHealthInterval,
+		RequestHeaders:  proxyRequestHeader,
+		ResponseHeaders: proxyResponseHeader,
+	})
+	if err != nil {
+		return nil, err
+	}
+	proxy.OnHealthChange = func(target string, healthy bool) {
+		if healthy {
+			appLog.Infof("Upstream %s voltou a responder", target)
+		} else {
+			appLog.Warnf("Upstream %s fora do ar", target)
+		}
+	}
+	proxy.StartHealthChecks(ctx)
+	mux.Handle("/__proxy", proxy.StatusHandler())
+
+	appLog.Infof("Proxy reverso com %d rota(s) (%s, estado em /__proxy)", len(proxyRoutes), proxyStrategy)
+	for _, route := range proxy.Status() {
+		if route.Directory != "" {
+			verbosePrint(cmd, "Rota: %s -> %s\n", route.Prefix, route.Directory)
+		}
+		for _, u := range route.Upstreams {
+			verbosePrint(cmd, "Rota: %s -> %s\n", route.Prefix, u.URL)
+		}
+	}
+	return proxy, nil
+}
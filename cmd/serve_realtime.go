@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	wsEnabled   bool
+	sseEnabled  bool
+	sseFile     string
+	sseStdin    bool
+	sseInterval time.Duration
+)
+
+func init() {
+	serveCmd.Flags().BoolVar(&wsEnabled, "ws", false, "Registra os endpoints WebSocket /ws/echo e /ws/broadcast")
+	serveCmd.Flags().BoolVar(&sseEnabled, "sse", false, "Registra o stream de Server-Sent Events em /sse (padrão: um evento tick por intervalo)")
+	serveCmd.Flags().StringVar(&sseFile, "sse-file", "", "Arquivo cujas linhas são enviadas em /sse, em ciclo, uma por intervalo (habilita --sse)")
+	serveCmd.Flags().BoolVar(&sseStdin, "sse-stdin", false, "Envia em /sse cada linha lida da entrada padrão (habilita --sse)")
+	serveCmd.Flags().DurationVar(&sseInterval, "sse-interval", time.Second, "Intervalo entre os eventos do timer ou do --sse-file")
+}
+
+// registerRealtime registra os endpoints WebSocket e SSE habilitados; as conexões
+// são encerradas quando o contexto é cancelado
+func registerRealtime(ctx context.Context, cmd *cobra.Command, mux *http.ServeMux) error {
+	if wsEnabled {
+		hub := server.NewWebSocketHub()
+		mux.Handle("/ws/echo", hub.EchoHandler())
+		mux.Handle("/ws/broadcast", hub.BroadcastHandler())
+		context.AfterFunc(ctx, hub.Close)
+		appLog.Infof("WebSocket em /ws/echo e /ws/broadcast")
+	}
+
+	if !sseEnabled && sseFile == "" && !sseStdin {
+		return nil
+	}
+	if sseFile != "" && sseStdin {
+		return fmt.Errorf("use apenas uma fonte de eventos entre --sse-file e --sse-stdin")
+	}
+	if sseInterval <= 0 && (sseFile != "" || !sseStdin) {
+		return fmt.Errorf("--sse-interval deve ser positivo")
+	}
+
+	broker := server.NewSSEBroker()
+	mux.Handle("GET /sse", broker)
+	context.AfterFunc(ctx, broker.Close)
+
+	switch {
+	case sseFile != "":
+		if _, err := os.Stat(sseFile); err != nil {
+			return fmt.Errorf("arquivo de eventos inválido: %w", err)
+		}
+		go streamSSEFile(ctx, broker, sseFile)
+		appLog.Infof("Server-Sent Events em /sse a partir de %s (a cada %v)", sseFile, sseInterval)
+	case sseStdin:
+		go func() {
+			if _, err := broker.Stream(ctx, cmd.InOrStdin(), 0); err != nil && ctx.Err() == nil {
+				appLog.Errorf("Erro ao ler eventos da entrada padrão: %v", err)
+			}
+		}()
+		appLog.Infof("Server-Sent Events em /sse a partir da entrada padrão")
+	default:
+		go broker.Tick(ctx, sseInterval)
+		appLog.Infof("Server-Sent Events em /sse (tick a cada %v)", sseInterval)
+	}
+	return nil
+}
+
+// streamSSEFile publica as linhas do arquivo em ciclo até o contexto ser cancelado
+func streamSSEFile(ctx context.Context, broker *server.SSEBroker, path string) {
+	for ctx.Err() == nil {
+		f, err := os.Open(path)
+		if err != nil {
+			appLog.Errorf("Erro ao abrir arquivo de eventos: %v", err)
+			return
+		}
+		n, err := broker.Stream(ctx, f, sseInterval)
+		f.Close()
+		if err != nil && ctx.Err() == nil {
+			appLog.Errorf("Erro ao ler arquivo de eventos: %v", err)
+			return
+		}
+		if n == 0 {
+			appLog.Warnf("Arquivo de eventos %s sem linhas; nada a enviar em /sse", path)
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/CristianSsousa/go-bast-cli/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tlsCert         string
+	tlsKey          string
+	tlsSelfSigned   bool
+	tlsRedirectPort int
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Arquivo PEM do certificado TLS")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", "", "Arquivo PEM da chave privada TLS")
+	serveCmd.Flags().BoolVar(&tlsSelfSigned, "tls-self-signed", false,
+		"Gera (ou reutiliza de ~/.bast/certs) um certificado autoassinado para localhost")
+	serveCmd.Flags().IntVar(&tlsRedirectPort, "tls-redirect-port", 0,
+		"Porta HTTP adicional que redireciona para HTTPS (requer TLS)")
+}
+
+// tlsEnabled indica se alguma opção de TLS foi informada
+func tlsEnabled() bool {
+	return tlsSelfSigned || tlsCert != "" || tlsKey != ""
+}
+
+// buildTLSConfig carrega o certificado informado ou o autoassinado; retorna nil sem TLS
+func buildTLSConfig(cmd *cobra.Command) (*tls.Config, error) {
+	if !tlsEnabled() {
+		if tlsRedirectPort != 0 {
+			return nil, fmt.Errorf("--tls-redirect-port requer --tls-cert/--tls-key ou --tls-self-signed")
+		}
+		return nil, nil
+	}
+	if tlsSelfSigned && (tlsCert != "" || tlsKey != "") {
+		return nil, fmt.Errorf("use --tls-self-signed ou --tls-cert/--tls-key, não ambos")
+	}
+
+	var cert tls.Certificate
+	if tlsSelfSigned {
+		configDir, err := utils.GetConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		selfSigned, err := server.LoadOrCreateSelfSigned(filepath.Join(configDir, constants.CertsDirName), server.SelfSignedHosts())
+		if err != nil {
+			return nil, err
+		}
+		if selfSigned.Created {
+			appLog.Infof("Certificado autoassinado gerado em %s", selfSigned.CertPath)
+		} else {
+			appLog.Infof("Reutilizando certificado autoassinado de %s", selfSigned.CertPath)
+		}
+		appLog.Infof("  Hosts:      %v", selfSigned.Hosts)
+		appLog.Infof("  Válido até: %s", selfSigned.NotAfter.Format(time.DateOnly))
+		appLog.Infof("  SHA-256:    %s", selfSigned.Fingerprint)
+		cert = selfSigned.Certificate
+	} else {
+		if tlsCert == "" || tlsKey == "" {
+			return nil, fmt.Errorf("--tls-cert e --tls-key devem ser informados juntos")
+		}
+		pair, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao carregar certificado TLS: %w", err)
+		}
+		cert = pair
+		verbosePrint(cmd, "Certificado TLS carregado de %s\n", tlsCert)
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}, nil
+}
+
+// startRedirectServer inicia o listener HTTP que redireciona para HTTPS, se configurado
+func startRedirectServer(ctx context.Context, host, httpsPort string, grace time.Duration) (*sync.WaitGroup, error) {
+	var wg sync.WaitGroup
+	if tlsRedirectPort == 0 {
+		return &wg, nil
+	}
+	if tlsRedirectPort < constants.MinPort || tlsRedirectPort > constants.MaxPort {
+		return nil, fmt.Errorf("--tls-redirect-port: "+constants.ErrInvalidPort, constants.MinPort, constants.MaxPort)
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(tlsRedirectPort))
+	ln, err := net.Listen(constants.TCPProtocol, addr)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar redirecionamento HTTP: %w", err)
+	}
+	redirect := server.New(&http.Server{
+		Handler:           server.RedirectToHTTPS(httpsPort),
+		ReadHeaderTimeout: 10 * time.Second,
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := redirect.Serve(ctx, ln, grace); err != nil {
+			appLog.Errorf("Erro no redirecionamento HTTP: %v", err)
+		}
+	}()
+	appLog.Infof("Redirecionando http://%s para HTTPS", addr)
+	return &wg, nil
+}
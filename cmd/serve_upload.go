@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/server"
+	"github.com/CristianSsousa/go-bast-cli/pkg/utils"
+)
+
+var (
+	uploadDir     string
+	uploadMaxSize string
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&uploadDir, "upload-dir", "", "Modo de upload: página para envio de arquivos e endpoint multipart/PUT que grava no diretório")
+	serveCmd.Flags().StringVar(&uploadMaxSize, "upload-max-size", "1GB", "Tamanho máximo de cada arquivo enviado (0 para ilimitado)")
+}
+
+// buildUploadHandler cria o handler do modo --upload-dir
+func buildUploadHandler() (http.Handler, error) {
+	maxSize, err := utils.ParseSize(uploadMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("--upload-max-size: %w", err)
+	}
+	upload, err := server.NewUploadHandler(uploadDir, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	upload.OnUpload = func(file server.UploadedFile) {
+		appLog.Infof("Recebido %s (%d bytes, sha256 %s)", file.Name, file.Size, file.SHA256)
+	}
+
+	limit := "sem limite"
+	if maxSize > 0 {
+		limit = "até " + uploadMaxSize + " por arquivo"
+	}
+	appLog.Infof("Recebendo arquivos em %s (%s)", upload.Dir(), limit)
+	return upload, nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/CristianSsousa/go-bast-cli/internal/constants"
 	"github.com/spf13/viper"
@@ -34,9 +36,106 @@ type LoggingConfig struct {
 
 // ServerConfig configurações do servidor
 type ServerConfig struct {
-	DefaultHost string `mapstructure:"default_host"`
-	DefaultPort int    `mapstructure:"default_port"`
-	Timeout     int    `mapstructure:"timeout"`
+	DefaultHost string                `mapstructure:"default_host"`
+	DefaultPort int                   `mapstructure:"default_port"`
+	Timeout     int                   `mapstructure:"timeout"`
+	Health      HealthConfig          `mapstructure:"health"`
+	Auth        AuthConfig            `mapstructure:"auth"`
+	CORS        CORSConfig            `mapstructure:"cors"`
+	Compression CompressionConfig     `mapstructure:"compression"`
+	Security    SecurityHeadersConfig `mapstructure:"security_headers"`
+	Limits      LimitsConfig          `mapstructure:"limits"`
+	Chaos       ChaosConfig           `mapstructure:"chaos"`
+}
+
+// ChaosConfig injeção de falhas para testar a resiliência dos clientes
+type ChaosConfig struct {
+	Enabled bool        `mapstructure:"enabled" json:"enabled"`
+	Seed    int64       `mapstructure:"seed" json:"seed"`     // semente do sorteio (0 usa o relógio)
+	Global  ChaosRule   `mapstructure:"global" json:"global"` // regra aplicada quando nenhuma rota coincide
+	Routes  []ChaosRule `mapstructure:"routes" json:"routes"` // regras por caminho, a primeira que coincidir vence
+	Exempt  []string    `mapstructure:"exempt" json:"exempt"` // caminhos nunca afetados
+}
+
+// ChaosRule falhas injetadas em um conjunto de caminhos
+type ChaosRule struct {
+	Path         string  `mapstructure:"path" json:"path,omitempty"`                   // ex.: /api/* (apenas em routes)
+	Latency      string  `mapstructure:"latency" json:"latency,omitempty"`             // 100ms, uniform:50ms,200ms ou normal:100ms,20ms
+	ErrorRate    float64 `mapstructure:"error_rate" json:"error_rate,omitempty"`       // fração (0-1) de respostas com erro
+	ErrorCodes   []int   `mapstructure:"error_codes" json:"error_codes,omitempty"`     // status sorteados (padrão: 500, 502, 503)
+	ResetRate    float64 `mapstructure:"reset_rate" json:"reset_rate,omitempty"`       // fração de conexões reiniciadas
+	TruncateRate float64 `mapstructure:"truncate_rate" json:"truncate_rate,omitempty"` // fração de corpos truncados
+}
+
+// LimitsConfig limites de taxa, conexões e tamanho de corpo do servidor
+type LimitsConfig struct {
+	Rate           float64  `mapstructure:"rate"`            // requisições/s por IP (0 desabilita)
+	Burst          int      `mapstructure:"burst"`           // rajada por IP (padrão: rate)
+	GlobalRate     float64  `mapstructure:"global_rate"`     // requisições/s no total (0 desabilita)
+	GlobalBurst    int      `mapstructure:"global_burst"`    // rajada global (padrão: global_rate)
+	MaxConnections int      `mapstructure:"max_connections"` // conexões simultâneas (0 ilimitado)
+	MaxBodySize    string   `mapstructure:"max_body_size"`   // ex.: 10MB (vazio ilimitado)
+	Exempt         []string `mapstructure:"exempt"`          // caminhos sem limite de taxa
+}
+
+// CORSConfig cabeçalhos CORS e tratamento de preflight (OPTIONS)
+type CORSConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`
+	Origins       []string `mapstructure:"origins"` // "*" ou origens; aceita "https://*.exemplo.com"
+	Methods       []string `mapstructure:"methods"`
+	Headers       []string `mapstructure:"headers"` // "*" aceita os cabeçalhos pedidos no preflight
+	ExposeHeaders []string `mapstructure:"expose_headers"`
+	Credentials   bool     `mapstructure:"credentials"`
+	MaxAge        int      `mapstructure:"max_age"` // segundos
+}
+
+// CompressionConfig compressão das respostas negociada via Accept-Encoding
+type CompressionConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	MinSize    int      `mapstructure:"min_size"`   // bytes
+	Algorithms []string `mapstructure:"algorithms"` // br, gzip, deflate em ordem de preferência
+}
+
+// SecurityHeadersConfig cabeçalhos de segurança adicionados às respostas
+type SecurityHeadersConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	HSTS               string `mapstructure:"hsts"` // enviado apenas em conexões TLS
+	CSP                string `mapstructure:"csp"`
+	ContentTypeOptions string `mapstructure:"content_type_options"`
+	FrameOptions       string `mapstructure:"frame_options"`
+	ReferrerPolicy     string `mapstructure:"referrer_policy"`
+}
+
+// AuthConfig autenticação e restrição por IP do servidor
+type AuthConfig struct {
+	Realm  string     `mapstructure:"realm"`
+	Users  []AuthUser `mapstructure:"users"`  // usuários do basic auth
+	Tokens []string   `mapstructure:"tokens"` // tokens aceitos em Authorization: Bearer
+	Allow  []string   `mapstructure:"allow"`  // CIDRs permitidos (vazio permite todos)
+	Deny   []string   `mapstructure:"deny"`   // CIDRs bloqueados
+	Exempt []string   `mapstructure:"exempt"` // caminhos liberados; "/prefixo/*" libera o prefixo
+}
+
+// AuthUser usuário do basic auth com senha em hash bcrypt
+type AuthUser struct {
+	Username     string `mapstructure:"username"`
+	PasswordHash string `mapstructure:"password_hash"`
+}
+
+// HealthConfig verificações expostas em /health/live e /health/ready
+type HealthConfig struct {
+	Checks []HealthCheckConfig `mapstructure:"checks"`
+}
+
+// HealthCheckConfig uma verificação de saúde declarada na configuração
+type HealthCheckConfig struct {
+	Name     string        `mapstructure:"name"`
+	Type     string        `mapstructure:"type"`    // tcp, http, file, command
+	Target   string        `mapstructure:"target"`  // host:porta, URL ou caminho do arquivo
+	Command  []string      `mapstructure:"command"` // programa e argumentos (type: command)
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Optional bool          `mapstructure:"optional"` // falhas não tornam o servidor indisponível
+	Live     bool          `mapstructure:"live"`     // também executada em /health/live
 }
 
 // FeaturesConfig configurações de features
@@ -50,6 +149,17 @@ var (
 	Cfg *Config
 )
 
+// Origens possíveis de um valor de configuração, em ordem de precedência
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "variável de ambiente"
+	SourceFile    = "arquivo de configuração"
+	SourceDefault = "padrão"
+)
+
+// envKeyReplacer converte chaves aninhadas (server.default_port) em nomes de variáveis (SERVER_DEFAULT_PORT)
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
 // Init inicializa a configuração usando Viper
 func Init(configPath string) error {
 	viper.SetConfigName("config")
@@ -70,6 +180,7 @@ func Init(configPath string) error {
 
 	// Variáveis de ambiente
 	viper.SetEnvPrefix(constants.EnvPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
 	// Valores padrão
@@ -113,6 +224,30 @@ func setDefaults() {
 	viper.SetDefault("server.default_port", constants.DefaultPort)
 	viper.SetDefault("server.default_host", constants.DefaultHost)
 	viper.SetDefault("server.timeout", constants.DefaultTimeout)
+	viper.SetDefault("server.auth.realm", constants.AppName)
+	viper.SetDefault("server.auth.exempt", []string{"/health", "/health/*"})
+
+	viper.SetDefault("server.cors.enabled", false)
+	viper.SetDefault("server.cors.origins", []string{"*"})
+	viper.SetDefault("server.cors.methods", []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	viper.SetDefault("server.cors.headers", []string{"*"})
+	viper.SetDefault("server.cors.max_age", 600)
+
+	viper.SetDefault("server.compression.enabled", false)
+	viper.SetDefault("server.compression.min_size", 1024)
+	viper.SetDefault("server.compression.algorithms", []string{"br", "gzip", "deflate"})
+
+	viper.SetDefault("server.security_headers.enabled", false)
+	viper.SetDefault("server.security_headers.hsts", "max-age=31536000; includeSubDomains")
+	viper.SetDefault("server.security_headers.csp", "default-src 'self'")
+	viper.SetDefault("server.security_headers.content_type_options", "nosniff")
+	viper.SetDefault("server.security_headers.frame_options", "DENY")
+	viper.SetDefault("server.security_headers.referrer_policy", "no-referrer")
+
+	viper.SetDefault("server.limits.exempt", []string{"/health", "/health/*"})
+
+	viper.SetDefault("server.chaos.enabled", false)
+	viper.SetDefault("server.chaos.exempt", []string{"/health", "/health/*", "/metrics", "/__*"})
 
 	viper.SetDefault("features.auto_update", false)
 	viper.SetDefault("features.verbose", false)
@@ -178,6 +313,23 @@ func GetBool(key string) bool {
 	return viper.GetBool(key)
 }
 
+// EnvVar retorna o nome da variável de ambiente correspondente a uma chave
+func EnvVar(key string) string {
+	return constants.EnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// Source informa de onde vem o valor atual de uma chave: variável de ambiente,
+// arquivo de configuração ou valor padrão
+func Source(key string) string {
+	if _, ok := os.LookupEnv(EnvVar(key)); ok {
+		return SourceEnv
+	}
+	if viper.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
 // Reset reseta a configuração para estado inicial (útil para testes)
 func Reset() {
 	Cfg = nil
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -121,6 +122,111 @@ func TestDefaults(t *testing.T) {
 	assert.Equal(t, 8080, cfg.Server.DefaultPort)
 	assert.Equal(t, "0.0.0.0", cfg.Server.DefaultHost)
 	assert.Equal(t, 30, cfg.Server.Timeout)
+	assert.Equal(t, "bast", cfg.Server.Auth.Realm)
+	assert.Equal(t, []string{"/health", "/health/*"}, cfg.Server.Auth.Exempt)
 	assert.False(t, cfg.Features.AutoUpdate)
 	assert.False(t, cfg.Features.Verbose)
 }
+
+func TestEnvOverrideAndSource(t *testing.T) {
+	Cfg = nil
+	viper.Reset()
+	Init("/tmp/nonexistent-config-12345.yaml")
+	assert.Equal(t, SourceDefault, Source("server.default_port"))
+
+	t.Setenv("BAST_SERVER_DEFAULT_PORT", "9090")
+	Cfg = nil
+	viper.Reset()
+	Init("/tmp/nonexistent-config-12345.yaml")
+
+	assert.Equal(t, "BAST_SERVER_DEFAULT_PORT", EnvVar("server.default_port"))
+	assert.Equal(t, 9090, Get().Server.DefaultPort)
+	assert.Equal(t, SourceEnv, Source("server.default_port"))
+
+	// Limpar estado após teste
+	Cfg = nil
+	viper.Reset()
+}
+
+func TestSourceFromFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  timeout: 5\n"), 0644))
+
+	Cfg = nil
+	viper.Reset()
+	require.NoError(t, Init(configPath))
+
+	assert.Equal(t, 5, Get().Server.Timeout)
+	assert.Equal(t, SourceFile, Source("server.timeout"))
+	assert.Equal(t, SourceDefault, Source("server.default_host"))
+
+	// Limpar estado após teste
+	Cfg = nil
+	viper.Reset()
+}
+
+func TestHealthChecksFromFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `server:
+  health:
+    checks:
+      - name: banco
+        type: tcp
+        target: localhost:5432
+        timeout: 2s
+      - name: migracoes
+        type: command
+        command: ["./check.sh", "--rapido"]
+        optional: true
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	Cfg = nil
+	viper.Reset()
+	require.NoError(t, Init(configPath))
+
+	checks := Get().Server.Health.Checks
+	require.Len(t, checks, 2)
+	assert.Equal(t, "banco", checks[0].Name)
+	assert.Equal(t, 2*time.Second, checks[0].Timeout)
+	assert.False(t, checks[0].Optional)
+	assert.Equal(t, []string{"./check.sh", "--rapido"}, checks[1].Command)
+	assert.True(t, checks[1].Optional)
+
+	// Limpar estado após teste
+	Cfg = nil
+	viper.Reset()
+}
+
+func TestChaosFromFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `server:
+  chaos:
+    enabled: true
+    seed: 42
+    global:
+      latency: uniform:50ms,200ms
+      error_codes: [503]
+    routes:
+      - path: /api/*
+        error_rate: 0.1
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	Cfg = nil
+	viper.Reset()
+	require.NoError(t, Init(configPath))
+
+	chaos := Get().Server.Chaos
+	assert.True(t, chaos.Enabled)
+	assert.Equal(t, int64(42), chaos.Seed)
+	assert.Equal(t, "uniform:50ms,200ms", chaos.Global.Latency)
+	assert.Equal(t, []int{503}, chaos.Global.ErrorCodes)
+	require.Len(t, chaos.Routes, 1)
+	assert.Equal(t, 0.1, chaos.Routes[0].ErrorRate)
+	assert.Contains(t, chaos.Exempt, "/__*")
+
+	// Limpar estado após teste
+	Cfg = nil
+	viper.Reset()
+}
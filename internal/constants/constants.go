@@ -18,6 +18,9 @@ const (
 
 	// ConfigFileExample nome do arquivo de exemplo
 	ConfigFileExample = "config.yaml.example"
+
+	// CertsDirName subdiretório de configuração com os certificados gerados
+	CertsDirName = "certs"
 )
 
 // Logging constants
@@ -81,6 +84,12 @@ const (
 
 	// ConfigFilePerm permissões do arquivo de configuração
 	ConfigFilePerm = 0644
+
+	// PrivateDirPerm permissões de diretórios com dados sensíveis
+	PrivateDirPerm = 0700
+
+	// PrivateFilePerm permissões de arquivos sensíveis (ex.: chaves privadas)
+	PrivateFilePerm = 0600
 )
 
 // Messages
@@ -121,6 +130,9 @@ const (
 	// SuccessPortInUse porta em uso
 	SuccessPortInUse = "Porta %d em %s está EM USO"
 
+	// SuccessPortFree porta livre para bind local
+	SuccessPortFree = "Porta %d em %s está LIVRE para iniciar um servidor"
+
 	// SuccessConfigCreated configuração criada
 	SuccessConfigCreated = "Arquivo de configuração criado: %s"
 
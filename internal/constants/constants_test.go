@@ -17,6 +17,7 @@ func TestConfigConstants(t *testing.T) {
 	assert.NotEmpty(t, ConfigDirName)
 	assert.NotEmpty(t, ConfigFileName)
 	assert.NotEmpty(t, ConfigFileExample)
+	assert.NotEmpty(t, CertsDirName)
 }
 
 func TestLoggingConstants(t *testing.T) {
@@ -49,4 +50,6 @@ func TestEnvPrefix(t *testing.T) {
 func TestFilePermissions(t *testing.T) {
 	assert.NotZero(t, ConfigDirPerm)
 	assert.NotZero(t, ConfigFilePerm)
+	assert.Equal(t, 0700, PrivateDirPerm)
+	assert.Equal(t, 0600, PrivateFilePerm)
 }
@@ -0,0 +1,367 @@
+// Package daemon gerencia instâncias do bast executadas em segundo plano: arquivos
+// de PID, estado e log em ~/.bast/run e o ciclo de vida dos processos
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+	"github.com/CristianSsousa/go-bast-cli/pkg/utils"
+)
+
+// DefaultName nome da instância quando nenhum é informado
+const DefaultName = "default"
+
+// runDirName subdiretório do diretório de configuração com os arquivos das instâncias
+const runDirName = "run"
+
+// Extensões dos arquivos de cada instância
+const (
+	pidExt   = ".pid"
+	logExt   = ".log"
+	stateExt = ".json"
+)
+
+// stopPollInterval intervalo entre as verificações enquanto um processo encerra
+const stopPollInterval = 100 * time.Millisecond
+
+// killWait tempo aguardado pelo fim do processo após o SIGKILL
+const killWait = 5 * time.Second
+
+// ErrNotRunning a instância não tem processo em execução
+var ErrNotRunning = errors.New("instância não está em execução")
+
+// ErrStale o arquivo de PID é obsoleto: o processo terminou ou o PID foi
+// reutilizado pelo sistema para outro processo
+var ErrStale = errors.New("arquivo de PID obsoleto")
+
+// instanceFlag flag que identifica, na linha de comando, o processo de uma instância
+const instanceFlag = "--daemon-instance"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Instance estado publicado por uma instância depois de começar a escutar
+type Instance struct {
+	Name    string    `json:"name"`
+	PID     int       `json:"pid"`
+	Address string    `json:"address"` // URL base, ex.: http://127.0.0.1:8080
+	Started time.Time `json:"started"`
+	Args    []string  `json:"args"`
+	// ProcessStart instante de início do processo segundo o sistema (veja
+	// ProcessStart), para distinguir o processo de outro que reutilize o PID
+	ProcessStart uint64 `json:"process_start,omitempty"`
+}
+
+// ValidateName verifica se o nome pode ser usado nos arquivos da instância
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("nome de instância inválido '%s': use letras, dígitos, '.', '-' e '_' (até 64 caracteres)", name)
+	}
+	return nil
+}
+
+// DefaultDir retorna o diretório padrão das instâncias (~/.bast/run)
+func DefaultDir() (string, error) {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, runDirName), nil
+}
+
+// Store acessa os arquivos de PID, estado e log das instâncias em um diretório
+type Store struct {
+	dir string
+}
+
+// NewStore cria o Store para o diretório dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir retorna o diretório das instâncias
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// PIDFile retorna o caminho do arquivo de PID da instância
+func (s *Store) PIDFile(name string) string {
+	return filepath.Join(s.dir, name+pidExt)
+}
+
+// LogFile retorna o caminho do arquivo de log da instância
+func (s *Store) LogFile(name string) string {
+	return filepath.Join(s.dir, name+logExt)
+}
+
+// StateFile retorna o caminho do arquivo de estado da instância
+func (s *Store) StateFile(name string) string {
+	return filepath.Join(s.dir, name+stateExt)
+}
+
+// ensureDir cria o diretório das instâncias se não existir
+func (s *Store) ensureDir() error {
+	if err := os.MkdirAll(s.dir, constants.ConfigDirPerm); err != nil {
+		return fmt.Errorf("erro ao criar diretório %s: %w", s.dir, err)
+	}
+	return nil
+}
+
+// writeFile grava o arquivo de forma atômica (arquivo temporário + rename)
+func (s *Store) writeFile(path string, data []byte) error {
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	return nil
+}
+
+// WritePID grava o PID da instância
+func (s *Store) WritePID(name string, pid int) error {
+	return s.writeFile(s.PIDFile(name), []byte(strconv.Itoa(pid)+"\n"))
+}
+
+// ReadPID lê o PID da instância; retorna ErrNotRunning se não houver arquivo
+func (s *Store) ReadPID(name string) (int, error) {
+	data, err := os.ReadFile(s.PIDFile(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, ErrNotRunning
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler arquivo de PID: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("arquivo de PID inválido: %s", s.PIDFile(name))
+	}
+	return pid, nil
+}
+
+// WriteState publica o estado da instância
+func (s *Store) WriteState(inst Instance) error {
+	data, err := json.MarshalIndent(inst, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(s.StateFile(inst.Name), append(data, '\n'))
+}
+
+// ReadState lê o estado publicado pela instância; retorna ErrNotRunning se a
+// instância ainda não publicou (ou já removeu) o estado
+func (s *Store) ReadState(name string) (*Instance, error) {
+	data, err := os.ReadFile(s.StateFile(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotRunning
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler estado da instância: %w", err)
+	}
+	var inst Instance
+	if err := json.Unmarshal(data, &inst); err != nil {
+		return nil, fmt.Errorf("estado da instância inválido (%s): %w", s.StateFile(name), err)
+	}
+	return &inst, nil
+}
+
+// Lookup lê o PID da instância e confirma que o processo ainda é dela. Retorna
+// ErrNotRunning se não houver arquivo de PID e ErrStale, junto com o PID, se o
+// processo terminou ou o PID pertence agora a outro processo. O estado é
+// retornado apenas se foi publicado pelo mesmo processo
+func (s *Store) Lookup(name string) (int, *Instance, error) {
+	pid, err := s.ReadPID(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	inst, err := s.ReadState(name)
+	if err != nil || inst.PID != pid {
+		inst = nil
+	}
+	if !owns(name, pid, inst) {
+		return pid, nil, ErrStale
+	}
+	return pid, inst, nil
+}
+
+// owns verifica se o processo com o PID é o da instância. Com o início do
+// processo registrado no estado, ele precisa coincidir; sem estado (instância
+// iniciando), a linha de comando precisa conter --daemon-instance <nome>. Onde
+// o sistema não permite nenhuma das verificações, basta o processo existir
+func owns(name string, pid int, inst *Instance) bool {
+	if !Alive(pid) {
+		return false
+	}
+	if inst != nil && inst.ProcessStart != 0 {
+		if start, ok := ProcessStart(pid); ok {
+			return start == inst.ProcessStart
+		}
+	}
+	if args, ok := processArgs(pid); ok {
+		return hasInstanceFlag(args, name)
+	}
+	return true
+}
+
+// hasInstanceFlag verifica se os argumentos contêm --daemon-instance <nome>
+func hasInstanceFlag(args []string, name string) bool {
+	for i, arg := range args {
+		if arg == instanceFlag+"="+name || arg == instanceFlag && i+1 < len(args) && args[i+1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove apaga os arquivos de PID e estado da instância, preservando o log
+func (s *Store) Remove(name string) error {
+	for _, path := range []string{s.PIDFile(name), s.StateFile(name)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Names lista, em ordem alfabética, as instâncias com arquivo de PID
+func (s *Store) Names() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), pidExt); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// OpenLog abre o log da instância para acréscimo
+func (s *Store) OpenLog(name string) (*os.File, error) {
+	if err := s.ensureDir(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(s.LogFile(name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, constants.ConfigFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir log da instância: %w", err)
+	}
+	return f, nil
+}
+
+// Start inicia o executável desvinculado do terminal atual (nova sessão no Unix,
+// processo destacado no Windows), com saída e erro redirecionados para log
+func Start(exe string, args []string, log *os.File) (*exec.Cmd, error) {
+	c := exec.Command(exe, args...)
+	c.Stdout = log
+	c.Stderr = log
+	c.SysProcAttr = detachedAttr()
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("erro ao iniciar processo: %w", err)
+	}
+	return c, nil
+}
+
+// Stop pede o encerramento do processo e aguarda até timeout; depois disso, o
+// processo é morto. Retorna se foi preciso matá-lo
+func Stop(pid int, timeout time.Duration) (killed bool, err error) {
+	if !Alive(pid) {
+		return false, ErrNotRunning
+	}
+	if err := terminate(pid); err != nil {
+		return false, fmt.Errorf("erro ao encerrar processo %d: %w", pid, err)
+	}
+	if waitExit(pid, timeout) {
+		return false, nil
+	}
+	if err := kill(pid); err != nil && Alive(pid) {
+		return true, fmt.Errorf("erro ao matar processo %d: %w", pid, err)
+	}
+	if !waitExit(pid, killWait) {
+		return true, fmt.Errorf("processo %d continua em execução após SIGKILL", pid)
+	}
+	return true, nil
+}
+
+// waitExit aguarda o fim do processo até timeout
+func waitExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for Alive(pid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(stopPollInterval)
+	}
+	return true
+}
+
+// Tail retorna as últimas n linhas do arquivo
+func Tail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	// Lê blocos crescentes a partir do fim até conter n linhas completas
+	size := info.Size()
+	for chunk := int64(64 * 1024); ; chunk *= 4 {
+		offset := size - chunk
+		if offset < 0 {
+			offset = 0
+		}
+		buf := make([]byte, size-offset)
+		if _, err := f.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+		if offset > 0 {
+			// A primeira linha do bloco pode estar incompleta
+			lines = lines[1:]
+		}
+		if len(lines) >= n || offset == 0 {
+			if len(lines) > n {
+				lines = lines[len(lines)-n:]
+			}
+			if len(lines) == 1 && lines[0] == "" {
+				return nil, nil
+			}
+			return lines, nil
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"default", "docs", "api-2", "web_v1.2"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "../etc", "a/b", ".oculto", "com espaço", strings.Repeat("a", 65)} {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
+
+func TestStorePIDAndState(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "run"))
+
+	_, err := store.ReadPID("docs")
+	assert.ErrorIs(t, err, ErrNotRunning)
+	_, err = store.ReadState("docs")
+	assert.ErrorIs(t, err, ErrNotRunning)
+
+	require.NoError(t, store.WritePID("docs", 1234))
+	require.NoError(t, store.WritePID("api", 5678))
+	pid, err := store.ReadPID("docs")
+	require.NoError(t, err)
+	assert.Equal(t, 1234, pid)
+
+	started := time.Now().Truncate(time.Second)
+	require.NoError(t, store.WriteState(Instance{Name: "docs", PID: 1234, Address: "http://0.0.0.0:3000", Started: started}))
+	inst, err := store.ReadState("docs")
+	require.NoError(t, err)
+	assert.Equal(t, "http://0.0.0.0:3000", inst.Address)
+	assert.True(t, started.Equal(inst.Started))
+
+	names, err := store.Names()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "docs"}, names)
+
+	log, err := store.OpenLog("docs")
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	require.NoError(t, store.Remove("docs"))
+	assert.NoFileExists(t, store.PIDFile("docs"))
+	assert.NoFileExists(t, store.StateFile("docs"))
+	assert.FileExists(t, store.LogFile("docs"))
+	require.NoError(t, store.Remove("docs"))
+
+	require.NoError(t, os.WriteFile(store.PIDFile("ruim"), []byte("abc"), 0644))
+	_, err = store.ReadPID("ruim")
+	assert.Error(t, err)
+}
+
+func TestStoreNamesMissingDir(t *testing.T) {
+	names, err := NewStore(filepath.Join(t.TempDir(), "inexistente")).Names()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var b strings.Builder
+	for i := 1; i <= 10000; i++ {
+		fmt.Fprintf(&b, "linha %d\n", i)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0644))
+
+	lines, err := Tail(path, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linha 9998", "linha 9999", "linha 10000"}, lines)
+
+	// Mais linhas do que o primeiro bloco lido
+	lines, err = Tail(path, 9000)
+	require.NoError(t, err)
+	require.Len(t, lines, 9000)
+	assert.Equal(t, "linha 1001", lines[0])
+
+	lines, err = Tail(path, 20000)
+	require.NoError(t, err)
+	assert.Len(t, lines, 10000)
+
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	lines, err = Tail(path, 5)
+	require.NoError(t, err)
+	assert.Empty(t, lines)
+
+	_, err = Tail(filepath.Join(t.TempDir(), "nada.log"), 5)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
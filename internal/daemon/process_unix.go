@@ -0,0 +1,80 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// detachedAttr inicia o processo em uma nova sessão, sem terminal controlador
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// Alive verifica se existe um processo em execução com o PID
+func Alive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	// EPERM: o processo existe, mas pertence a outro usuário
+	if err != nil && !errors.Is(err, syscall.EPERM) {
+		return false
+	}
+	return !zombie(pid)
+}
+
+// zombie verifica, onde houver /proc, se o processo já terminou e aguarda apenas
+// ser recolhido pelo processo pai
+func zombie(pid int) bool {
+	fields, ok := procStat(pid)
+	return ok && fields[0] == "Z"
+}
+
+// ProcessStart retorna o instante de início do processo, em ticks do relógio
+// desde o boot (campo starttime de /proc/<pid>/stat); false onde não houver /proc
+func ProcessStart(pid int) (uint64, bool) {
+	fields, ok := procStat(pid)
+	if !ok || len(fields) < 20 {
+		return 0, false
+	}
+	start, err := strconv.ParseUint(fields[19], 10, 64)
+	return start, err == nil
+}
+
+// procStat lê os campos de /proc/<pid>/stat a partir do estado (terceiro campo),
+// que vem depois do nome do executável, entre parênteses
+func procStat(pid int) ([]string, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return nil, false
+	}
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return nil, false
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	return fields, len(fields) > 0
+}
+
+// processArgs retorna a linha de comando do processo (/proc/<pid>/cmdline);
+// false onde não houver /proc ou sem permissão para lê-la
+func processArgs(pid int) ([]string, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline")
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00"), true
+}
+
+// terminate pede o encerramento gracioso do processo (SIGTERM)
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// kill mata o processo (SIGKILL)
+func kill(pid int) error {
+	return syscall.Kill(pid, syscall.SIGKILL)
+}
@@ -0,0 +1,107 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startChild inicia um processo filho recolhido em segundo plano ao terminar;
+// args são acrescentados à linha de comando do shell
+func startChild(t *testing.T, script string, args ...string) *exec.Cmd {
+	t.Helper()
+	log, err := os.Create(filepath.Join(t.TempDir(), "child.log"))
+	require.NoError(t, err)
+	t.Cleanup(func() { log.Close() })
+	c, err := Start("/bin/sh", append([]string{"-c", script, "sh"}, args...), log)
+	require.NoError(t, err)
+	go func() { _ = c.Wait() }()
+	t.Cleanup(func() { _ = c.Process.Kill() })
+	return c
+}
+
+func TestStopGraceful(t *testing.T) {
+	c := startChild(t, "sleep 30")
+	require.True(t, Alive(c.Process.Pid))
+
+	killed, err := Stop(c.Process.Pid, 5*time.Second)
+	require.NoError(t, err)
+	assert.False(t, killed)
+	assert.False(t, Alive(c.Process.Pid))
+
+	_, err = Stop(c.Process.Pid, time.Second)
+	assert.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestStopKillsAfterTimeout(t *testing.T) {
+	// O shell ignora o SIGTERM e precisa ser morto
+	c := startChild(t, "trap '' TERM; while :; do sleep 0.05; done")
+	time.Sleep(100 * time.Millisecond)
+
+	killed, err := Stop(c.Process.Pid, 200*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, killed)
+	assert.False(t, Alive(c.Process.Pid))
+}
+
+func TestStoreLookup(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("sem /proc para identificar os processos")
+	}
+	store := NewStore(filepath.Join(t.TempDir(), "run"))
+
+	_, _, err := store.Lookup("docs")
+	assert.ErrorIs(t, err, ErrNotRunning)
+
+	// Processo da instância, identificado pela linha de comando enquanto não publica o estado
+	c := startChild(t, "sleep 30; true", "--daemon-instance", "docs")
+	pid := c.Process.Pid
+	require.NoError(t, store.WritePID("docs", pid))
+	require.Eventually(t, func() bool {
+		_, _, err := store.Lookup("docs")
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	start, ok := ProcessStart(pid)
+	require.True(t, ok)
+	require.NoError(t, store.WriteState(Instance{Name: "docs", PID: pid, ProcessStart: start}))
+	got, inst, err := store.Lookup("docs")
+	require.NoError(t, err)
+	assert.Equal(t, pid, got)
+	require.NotNil(t, inst)
+
+	// Mesmo PID, outro início: o PID foi reutilizado por outro processo
+	require.NoError(t, store.WriteState(Instance{Name: "docs", PID: pid, ProcessStart: start + 1}))
+	got, inst, err = store.Lookup("docs")
+	assert.ErrorIs(t, err, ErrStale)
+	assert.Equal(t, pid, got)
+	assert.Nil(t, inst)
+
+	// Processo alheio sem estado publicado
+	other := startChild(t, "sleep 30; true")
+	require.NoError(t, store.WritePID("api", other.Process.Pid))
+	_, _, err = store.Lookup("api")
+	assert.ErrorIs(t, err, ErrStale)
+
+	// Processo encerrado
+	require.NoError(t, c.Process.Kill())
+	require.Eventually(t, func() bool { return !Alive(pid) }, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, store.WriteState(Instance{Name: "docs", PID: pid, ProcessStart: start}))
+	_, _, err = store.Lookup("docs")
+	assert.ErrorIs(t, err, ErrStale)
+}
+
+func TestHasInstanceFlag(t *testing.T) {
+	assert.True(t, hasInstanceFlag([]string{"bast", "serve", "--daemon-instance", "docs", "-p", "3000"}, "docs"))
+	assert.True(t, hasInstanceFlag([]string{"bast", "serve", "--daemon-instance=docs"}, "docs"))
+	assert.False(t, hasInstanceFlag([]string{"bast", "serve", "--daemon-instance", "api"}, "docs"))
+	assert.False(t, hasInstanceFlag([]string{"bast", "serve", "--daemon-instance"}, "docs"))
+	assert.False(t, hasInstanceFlag([]string{"vim", "docs"}, "docs"))
+}
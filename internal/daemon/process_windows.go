@@ -0,0 +1,72 @@
+//go:build windows
+
+package daemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// Constantes da API do Windows ausentes no pacote syscall
+const (
+	detachedProcess                = 0x00000008
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+)
+
+// detachedAttr inicia o processo destacado do console atual
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+		HideWindow:    true,
+	}
+}
+
+// Alive verifica se existe um processo ativo com o PID
+func Alive(pid int) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// Acesso negado: o processo existe, mas pertence a outro usuário
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// ProcessStart retorna o instante de criação do processo, em nanossegundos
+func ProcessStart(pid int) (uint64, bool) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return 0, false
+	}
+	defer syscall.CloseHandle(h)
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(h, &creation, &exit, &kernel, &user); err != nil {
+		return 0, false
+	}
+	return uint64(creation.Nanoseconds()), true
+}
+
+// processArgs não está disponível no Windows; a identificação usa ProcessStart
+func processArgs(pid int) ([]string, bool) {
+	return nil, false
+}
+
+// terminate encerra o processo; o Windows não entrega sinais a processos
+// destacados do console, então o encerramento não é gracioso
+func terminate(pid int) error {
+	return kill(pid)
+}
+
+// kill mata o processo
+func kill(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
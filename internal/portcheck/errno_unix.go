@@ -0,0 +1,18 @@
+//go:build !windows
+
+package portcheck
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isRefused verifica se a conexão foi recusada (nenhum processo na porta)
+func isRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isAddrInUse verifica se o bind falhou porque a porta já está em uso
+func isAddrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package portcheck
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Códigos Winsock, que o pacote syscall não mapeia para ECONNREFUSED e EADDRINUSE
+const (
+	wsaeaddrinuse   = syscall.Errno(10048)
+	wsaeconnrefused = syscall.Errno(10061)
+	wsaeacces       = syscall.Errno(10013)
+)
+
+// isRefused verifica se a conexão foi recusada (nenhum processo na porta)
+func isRefused(err error) bool {
+	return errors.Is(err, wsaeconnrefused) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isAddrInUse verifica se o bind falhou porque a porta já está em uso; o Windows
+// também responde WSAEACCES quando a porta está reservada com uso exclusivo
+func isAddrInUse(err error) bool {
+	return errors.Is(err, wsaeaddrinuse) || errors.Is(err, wsaeacces) || errors.Is(err, syscall.EADDRINUSE)
+}
@@ -0,0 +1,59 @@
+package portcheck
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrOwnerUnsupported a identificação do processo dono não existe neste sistema
+var ErrOwnerUnsupported = errors.New("identificação do processo dono da porta disponível apenas no Linux")
+
+// ErrOwnerHidden o socket existe, mas o processo pertence a outro usuário e não
+// pode ser identificado sem privilégios
+var ErrOwnerHidden = errors.New("sem permissão para identificar o processo (tente com sudo)")
+
+// Socket socket encontrado nas tabelas do kernel
+type Socket struct {
+	Protocol string // tcp, tcp6, udp ou udp6
+	IP       net.IP
+	Port     int
+	State    string // LISTEN para TCP; vazio para UDP
+	UID      int
+	Inode    uint64
+}
+
+// Address endereço local do socket no formato host:porta
+func (s Socket) Address() string {
+	return net.JoinHostPort(s.IP.String(), strconv.Itoa(s.Port))
+}
+
+// Owner processo que mantém um socket aberto; sem permissão para inspecionar o
+// processo, PID fica zerado e Err é ErrOwnerHidden. O socket pode ter sido
+// criado por outro processo e herdado (workers do nginx, ativação por socket do
+// systemd), então o dono do processo é ProcessUID, e não Socket.UID
+type Owner struct {
+	Socket
+	PID        int
+	ProcessUID int // UID real do processo; -1 se não puder ser lido
+	Command    string
+	User       string // nome do dono do processo (ou do socket, se desconhecido)
+	Started    time.Time
+	Err        error
+}
+
+// sortOwners ordena por porta, PID e protocolo
+func sortOwners(owners []Owner) {
+	sort.SliceStable(owners, func(i, j int) bool {
+		a, b := owners[i], owners[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.PID != b.PID {
+			return a.PID < b.PID
+		}
+		return a.Protocol < b.Protocol
+	})
+}
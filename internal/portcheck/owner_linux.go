@@ -0,0 +1,285 @@
+//go:build linux
+
+package portcheck
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procRoot raiz do procfs; substituída nos testes
+var procRoot = "/proc"
+
+// clockTicks unidades por segundo dos tempos de /proc/<pid>/stat (USER_HZ, que é
+// 100 em praticamente todas as arquiteturas)
+const clockTicks = 100
+
+// tcpListen código do estado LISTEN nas tabelas /proc/net/tcp*
+const tcpListen = "0A"
+
+// socketTables tabelas de sockets consultadas, em IPv4 e IPv6
+var socketTables = []string{"tcp", "tcp6", "udp", "udp6"}
+
+// FindOwners identifica os processos que escutam nas portas: sockets TCP em
+// LISTEN e sockets UDP, em IPv4 e IPv6. Sockets de processos de outros usuários,
+// que só podem ser inspecionados com privilégios, aparecem com ErrOwnerHidden
+func FindOwners(ports ...int) ([]Owner, error) {
+	wanted := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		wanted[port] = true
+	}
+
+	sockets := make(map[uint64]Socket)
+	for _, table := range socketTables {
+		found, err := readSocketTable(table, wanted)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Tabela ausente: IPv6 desativado no kernel
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range found {
+			sockets[s.Inode] = s
+		}
+	}
+	if len(sockets) == 0 {
+		return nil, nil
+	}
+
+	pids, err := socketPIDs(sockets)
+	if err != nil {
+		return nil, err
+	}
+
+	boot := bootTime()
+	users := make(map[int]string)
+	var owners []Owner
+	for inode, s := range sockets {
+		name, ok := users[s.UID]
+		if !ok {
+			name = userName(s.UID)
+			users[s.UID] = name
+		}
+		if len(pids[inode]) == 0 {
+			owners = append(owners, Owner{Socket: s, ProcessUID: -1, User: name, Err: ErrOwnerHidden})
+			continue
+		}
+		for _, pid := range pids[inode] {
+			owner := Owner{Socket: s, PID: pid, ProcessUID: -1, User: name}
+			owner.Command, owner.Started, owner.Err = readProcess(pid, boot)
+			uid, err := processUID(pid)
+			switch {
+			case err == nil:
+				owner.ProcessUID = uid
+				if owner.User, ok = users[uid]; !ok {
+					owner.User = userName(uid)
+					users[uid] = owner.User
+				}
+			case owner.Err == nil:
+				owner.Err = err
+			}
+			owners = append(owners, owner)
+		}
+	}
+	sortOwners(owners)
+	return owners, nil
+}
+
+// readSocketTable lê /proc/net/<table> filtrando as portas desejadas
+func readSocketTable(table string, wanted map[int]bool) ([]Socket, error) {
+	f, err := os.Open(filepath.Join(procRoot, "net", table))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseSocketTable(f, table, wanted)
+}
+
+// parseSocketTable interpreta uma tabela no formato de /proc/net/tcp, mantendo
+// os sockets TCP em LISTEN e os UDP nas portas desejadas
+func parseSocketTable(r io.Reader, protocol string, wanted map[int]bool) ([]Socket, error) {
+	tcp := strings.HasPrefix(protocol, "tcp")
+	var sockets []Socket
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); first = false {
+		fields := strings.Fields(scanner.Text())
+		// Cabeçalho: sl local_address rem_address st ... uid timeout inode
+		if first || len(fields) < 10 {
+			continue
+		}
+		ip, port, err := parseHexAddress(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler /proc/net/%s: %w", protocol, err)
+		}
+		if !wanted[port] || tcp && fields[3] != tcpListen {
+			continue
+		}
+		uid, errUID := strconv.Atoi(fields[7])
+		inode, errInode := strconv.ParseUint(fields[9], 10, 64)
+		if errUID != nil || errInode != nil || inode == 0 {
+			continue
+		}
+		s := Socket{Protocol: protocol, IP: ip, Port: port, UID: uid, Inode: inode}
+		if tcp {
+			s.State = "LISTEN"
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// parseHexAddress interpreta o endereço "0100007F:1F90" das tabelas do kernel:
+// o IP vem em palavras de 32 bits na ordem de bytes da máquina e a porta em hexadecimal
+func parseHexAddress(s string) (net.IP, int, error) {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, 0, fmt.Errorf("endereço inválido '%s'", s)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("porta inválida em '%s'", s)
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || len(raw) != net.IPv4len && len(raw) != net.IPv6len {
+		return nil, 0, fmt.Errorf("IP inválido em '%s'", s)
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.NativeEndian.PutUint32(ip[i:], binary.BigEndian.Uint32(raw[i:]))
+	}
+	return ip, int(port), nil
+}
+
+// socketPIDs percorre os descritores de /proc/<pid>/fd e associa cada inode de
+// socket aos processos que o mantêm aberto; processos inacessíveis (de outros
+// usuários) ou encerrados durante a varredura são ignorados
+func socketPIDs(sockets map[uint64]Socket) (map[uint64][]int, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", procRoot, err)
+	}
+	pids := make(map[uint64][]int)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		fdDir := filepath.Join(procRoot, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		seen := make(map[uint64]bool)
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := socketInode(link)
+			if !ok || seen[inode] {
+				continue
+			}
+			if _, wanted := sockets[inode]; wanted {
+				seen[inode] = true
+				pids[inode] = append(pids[inode], pid)
+			}
+		}
+	}
+	return pids, nil
+}
+
+// socketInode extrai o inode do link "socket:[12345]" de um descritor
+func socketInode(link string) (uint64, bool) {
+	rest, ok := strings.CutPrefix(link, "socket:[")
+	if !ok || !strings.HasSuffix(rest, "]") {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(strings.TrimSuffix(rest, "]"), 10, 64)
+	return inode, err == nil
+}
+
+// readProcess lê a linha de comando e o horário de início do processo
+func readProcess(pid int, boot time.Time) (string, time.Time, error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("erro ao ler o processo %d: %w", pid, err)
+	}
+	command := strings.TrimSpace(string(bytes.ReplaceAll(bytes.TrimRight(cmdline, "\x00"), []byte{0}, []byte{' '})))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return command, time.Time{}, fmt.Errorf("erro ao ler o processo %d: %w", pid, err)
+	}
+	// O nome do executável fica entre parênteses e pode conter espaços
+	open, end := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+	if command == "" && open >= 0 && end > open {
+		// Threads do kernel não têm linha de comando
+		command = "[" + string(stat[open+1:end]) + "]"
+	}
+	var started time.Time
+	if fields := strings.Fields(string(stat[end+1:])); len(fields) > 19 && !boot.IsZero() {
+		// starttime é o campo 22 de stat, o 20º depois do nome
+		if ticks, err := strconv.ParseUint(fields[19], 10, 64); err == nil {
+			started = boot.Add(time.Duration(ticks) * time.Second / clockTicks)
+		}
+	}
+	return command, started, nil
+}
+
+// processUID lê o UID real do processo (primeiro valor da linha Uid: de
+// /proc/<pid>/status)
+func processUID(pid int) (int, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return -1, fmt.Errorf("erro ao ler o processo %d: %w", pid, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "Uid:"); ok {
+			if fields := strings.Fields(value); len(fields) > 0 {
+				if uid, err := strconv.Atoi(fields[0]); err == nil {
+					return uid, nil
+				}
+			}
+		}
+	}
+	return -1, fmt.Errorf("UID do processo %d não encontrado em status", pid)
+}
+
+// bootTime lê o horário de inicialização do sistema (btime em /proc/stat)
+func bootTime() time.Time {
+	data, err := os.ReadFile(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			if sec, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+				return time.Unix(sec, 0)
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// userName resolve o nome do usuário pelo UID, usando o número se não existir
+func userName(uid int) string {
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(uid)
+}
@@ -0,0 +1,136 @@
+//go:build linux
+
+package portcheck
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHexAddress(t *testing.T) {
+	// As tabelas do kernel usam a ordem de bytes da máquina (little-endian aqui)
+	ip, port, err := parseHexAddress("0100007F:1F90")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", ip.String())
+	assert.Equal(t, 8080, port)
+
+	ip, port, err = parseHexAddress("00000000000000000000000001000000:0035")
+	require.NoError(t, err)
+	assert.Equal(t, "::1", ip.String())
+	assert.Equal(t, 53, port)
+
+	for _, invalid := range []string{"0100007F", "0100007F:XYZ", "01007F:1F90"} {
+		_, _, err := parseHexAddress(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSocketInode(t *testing.T) {
+	inode, ok := socketInode("socket:[12345]")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(12345), inode)
+
+	for _, link := range []string{"/dev/null", "pipe:[12]", "socket:[abc]", "socket:[12"} {
+		_, ok := socketInode(link)
+		assert.False(t, ok, link)
+	}
+}
+
+// writeProc cria um arquivo na árvore falsa de /proc
+func writeProc(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+const tableHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+func TestFindOwnersFakeProc(t *testing.T) {
+	root := t.TempDir()
+	previous := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = previous })
+
+	writeProc(t, root, "net/tcp", tableHeader+
+		"   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 555 1 0 100 0 0 10 0\n"+
+		// Conexão estabelecida na mesma porta: não é o socket que escuta
+		"   1: 0100007F:1F90 0100007F:D431 01 00000000:00000000 00:00000000 00000000     0        0 556 1 0 20 4 30 10 -1\n"+
+		"   2: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 600 1 0 100 0 0 10 0\n")
+	writeProc(t, root, "net/tcp6", tableHeader+
+		"   0: 00000000000000000000000000000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 557 1 0 100 0 0 10 0\n")
+	writeProc(t, root, "net/udp", tableHeader+
+		"  10: 00000000:1F90 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 558 2 0 0\n")
+	writeProc(t, root, "stat", "cpu  1 2 3\nbtime 1700000000\n")
+
+	writeProc(t, root, "123/cmdline", "python3\x00-m\x00http.server\x00")
+	writeProc(t, root, "123/stat", "123 (python3) S 1 123 123 0 -1 4194304 100 0 0 0 0 0 0 0 20 0 1 0 500 1000 2000\n")
+	// Socket criado pelo root (UID 0 na tabela) e herdado por um processo de outro usuário
+	writeProc(t, root, "123/status", "Name:\tpython3\nUid:\t4321\t4321\t4321\t4321\nGid:\t4321\t4321\t4321\t4321\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "123/fd"), 0755))
+	for fd, target := range map[string]string{"3": "socket:[555]", "4": "socket:[558]", "5": "/dev/null", "6": "socket:[555]"} {
+		require.NoError(t, os.Symlink(target, filepath.Join(root, "123/fd", fd)))
+	}
+	// O socket 557 não pertence a nenhum processo visível
+
+	owners, err := FindOwners(8080)
+	require.NoError(t, err)
+	require.Len(t, owners, 3)
+
+	hidden := owners[0]
+	assert.Zero(t, hidden.PID)
+	assert.Equal(t, -1, hidden.ProcessUID)
+	assert.ErrorIs(t, hidden.Err, ErrOwnerHidden)
+	assert.Equal(t, "tcp6", hidden.Protocol)
+	assert.Equal(t, "[::]:8080", hidden.Address())
+
+	tcp := owners[1]
+	assert.Equal(t, 123, tcp.PID)
+	assert.NoError(t, tcp.Err)
+	assert.Equal(t, "tcp", tcp.Protocol)
+	assert.Equal(t, "LISTEN", tcp.State)
+	assert.Equal(t, "127.0.0.1:8080", tcp.Address())
+	assert.Equal(t, "python3 -m http.server", tcp.Command)
+	assert.Equal(t, 0, tcp.UID)
+	assert.Equal(t, 4321, tcp.ProcessUID)
+	assert.Equal(t, "4321", tcp.User)
+	assert.True(t, time.Unix(1700000005, 0).Equal(tcp.Started))
+
+	udp := owners[2]
+	assert.Equal(t, 123, udp.PID)
+	assert.Equal(t, "udp", udp.Protocol)
+	assert.Empty(t, udp.State)
+
+	owners, err = FindOwners(9999)
+	require.NoError(t, err)
+	assert.Empty(t, owners)
+}
+
+func TestFindOwners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	owners, err := FindOwners(port)
+	require.NoError(t, err)
+	var found *Owner
+	for i := range owners {
+		if owners[i].PID == os.Getpid() {
+			found = &owners[i]
+		}
+	}
+	require.NotNil(t, found, "o processo do teste deve ser o dono da porta %d", port)
+	assert.Equal(t, "tcp", found.Protocol)
+	assert.Equal(t, port, found.Port)
+	assert.True(t, strings.HasSuffix(strings.Fields(found.Command)[0], ".test"), found.Command)
+	assert.False(t, found.Started.IsZero())
+	assert.Equal(t, os.Getuid(), found.ProcessUID)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package portcheck
+
+// FindOwners identifica os processos que escutam nas portas; disponível apenas
+// no Linux
+func FindOwners(ports ...int) ([]Owner, error) {
+	return nil, ErrOwnerUnsupported
+}
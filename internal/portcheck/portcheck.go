@@ -0,0 +1,150 @@
+package portcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+)
+
+// Result resultado da tentativa de conexão TCP em host:porta
+type Result struct {
+	Host       string
+	Port       int
+	Address    string
+	Open       bool // a conexão foi estabelecida
+	TimedOut   bool // a tentativa expirou sem resposta
+	Err        error
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	Duration   time.Duration
+}
+
+// Probe tenta abrir uma conexão TCP em host:porta dentro do timeout e a fecha em seguida
+func Probe(ctx context.Context, host string, port int, timeout time.Duration) Result {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	result := Result{Host: host, Port: port, Address: address}
+
+	dialer := net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, constants.TCPProtocol, address)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		var netErr net.Error
+		result.TimedOut = errors.As(err, &netErr) && netErr.Timeout()
+		return result
+	}
+	defer conn.Close()
+
+	result.Open = true
+	result.LocalAddr = conn.LocalAddr()
+	result.RemoteAddr = conn.RemoteAddr()
+	return result
+}
+
+// ProbeAddress é como Probe, recebendo o endereço no formato host:porta
+func ProbeAddress(ctx context.Context, address string, timeout time.Duration) (Result, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return Result{}, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < constants.MinPort || port > constants.MaxPort {
+		return Result{}, errors.New("porta inválida em " + address)
+	}
+	return Probe(ctx, host, port, timeout), nil
+}
+
+// ParseRange interpreta uma faixa de portas no formato "inicio-fim" (ou uma porta única)
+func ParseRange(spec string) (from, to int, err error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(spec), "-")
+	if !isRange {
+		last = first
+	}
+	from, errFrom := strconv.Atoi(strings.TrimSpace(first))
+	to, errTo := strconv.Atoi(strings.TrimSpace(last))
+	if !isRange && errFrom != nil {
+		return 0, 0, fmt.Errorf("porta inválida '%s'", spec)
+	}
+	if errFrom != nil || errTo != nil {
+		return 0, 0, fmt.Errorf("faixa de portas inválida '%s': use inicio-fim, ex.: 8000-8100", spec)
+	}
+	if from < constants.MinPort || to > constants.MaxPort || from > to {
+		return 0, 0, fmt.Errorf("faixa de portas inválida '%s': as portas devem estar entre %d e %d, em ordem crescente",
+			spec, constants.MinPort, constants.MaxPort)
+	}
+	return from, to, nil
+}
+
+// ListenFirst escuta em host na primeira porta livre da lista, mantendo o
+// listener aberto para que a porta não seja tomada por outro processo. Só
+// passa para a próxima porta quando a atual está em uso; outros erros, como
+// host inexistente ou sem permissão, são retornados imediatamente
+func ListenFirst(host string, ports []int) (net.Listener, error) {
+	if len(ports) == 0 {
+		return nil, errors.New("nenhuma porta para testar")
+	}
+	var lastErr error
+	for _, port := range ports {
+		ln, err := net.Listen(constants.TCPProtocol, net.JoinHostPort(host, strconv.Itoa(port)))
+		if err == nil {
+			return ln, nil
+		}
+		if !isAddrInUse(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	if len(ports) == 1 {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("nenhuma porta livre entre as %d testadas: %w", len(ports), lastErr)
+}
+
+// Estados do teste de bind local
+const (
+	BindFree  = "livre"
+	BindInUse = "em uso"
+	BindError = "erro"
+)
+
+// BindResult resultado da tentativa de escutar em host:porta
+type BindResult struct {
+	Host    string
+	Port    int
+	Address string
+	Err     error
+}
+
+// State classifica o teste: livre (o bind funcionou), em uso ou erro (permissão
+// negada, endereço inexistente na máquina etc.)
+func (r BindResult) State() string {
+	switch {
+	case r.Err == nil:
+		return BindFree
+	case isAddrInUse(r.Err):
+		return BindInUse
+	default:
+		return BindError
+	}
+}
+
+// Bind tenta escutar em host:porta e libera a porta em seguida, respondendo se
+// um servidor poderia ser iniciado ali
+func Bind(host string, port int) BindResult {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	result := BindResult{Host: host, Port: port, Address: address}
+	ln, err := net.Listen(constants.TCPProtocol, address)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	_ = ln.Close()
+	return result
+}
@@ -0,0 +1,102 @@
+package portcheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	open := Probe(context.Background(), "127.0.0.1", port, time.Second)
+	assert.True(t, open.Open)
+	assert.NoError(t, open.Err)
+	assert.Equal(t, ln.Addr().String(), open.Address)
+	assert.NotNil(t, open.RemoteAddr)
+
+	require.NoError(t, ln.Close())
+	closed := Probe(context.Background(), "127.0.0.1", port, time.Second)
+	assert.False(t, closed.Open)
+	assert.False(t, closed.TimedOut)
+	assert.Error(t, closed.Err)
+}
+
+func TestProbeAddress(t *testing.T) {
+	_, err := ProbeAddress(context.Background(), "localhost", time.Second)
+	assert.Error(t, err)
+	_, err = ProbeAddress(context.Background(), "localhost:70000", time.Second)
+	assert.Error(t, err)
+}
+
+func TestParseRange(t *testing.T) {
+	from, to, err := ParseRange("8000-8100")
+	require.NoError(t, err)
+	assert.Equal(t, 8000, from)
+	assert.Equal(t, 8100, to)
+
+	from, to, err = ParseRange("3000")
+	require.NoError(t, err)
+	assert.Equal(t, 3000, from)
+	assert.Equal(t, 3000, to)
+
+	for _, spec := range []string{"", "abc", "8100-8000", "0-10", "65000-70000", "1-2-3"} {
+		_, _, err := ParseRange(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestListenFirst(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	// Reserva uma porta livre e a libera para o teste
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	freePort := free.Addr().(*net.TCPAddr).Port
+	require.NoError(t, free.Close())
+
+	ln, err := ListenFirst("127.0.0.1", []int{busyPort, freePort})
+	require.NoError(t, err)
+	defer ln.Close()
+	assert.Equal(t, freePort, ln.Addr().(*net.TCPAddr).Port)
+
+	_, err = ListenFirst("127.0.0.1", []int{busyPort})
+	assert.Error(t, err)
+	_, err = ListenFirst("127.0.0.1", []int{busyPort, busyPort})
+	assert.ErrorContains(t, err, "nenhuma porta livre")
+	_, err = ListenFirst("127.0.0.1", nil)
+	assert.Error(t, err)
+
+	// Erros que não sejam de porta em uso não passam para a próxima porta
+	_, err = ListenFirst("203.0.113.1", []int{freePort, freePort + 1})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "nenhuma porta livre")
+	assert.False(t, isAddrInUse(err))
+}
+
+func TestBind(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	busy := Bind("127.0.0.1", port)
+	assert.Equal(t, BindInUse, busy.State())
+	assert.Error(t, busy.Err)
+
+	require.NoError(t, ln.Close())
+	free := Bind("127.0.0.1", port)
+	assert.Equal(t, BindFree, free.State())
+	assert.NoError(t, free.Err)
+
+	// Endereço que não pertence a esta máquina (TEST-NET-1)
+	assert.Equal(t, BindError, Bind("192.0.2.1", port).State())
+}
@@ -0,0 +1,231 @@
+package portcheck
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxTargets limite de combinações host:porta em uma varredura
+const MaxTargets = 1 << 20
+
+// maxCIDRHosts limite de endereços expandidos de um bloco CIDR
+const maxCIDRHosts = 1 << 16
+
+// Estados de uma porta
+const (
+	StateOpen     = "aberta"   // conexão estabelecida
+	StateClosed   = "fechada"  // conexão recusada
+	StateFiltered = "filtrada" // sem resposta até o timeout
+	StateError    = "erro"     // falha de DNS, rota ou rede
+)
+
+// Target host e porta a verificar
+type Target struct {
+	Host string
+	Port int
+}
+
+// State classifica o resultado: aberta, fechada (conexão recusada), filtrada
+// (sem resposta até o timeout) ou erro (DNS, rota inexistente e demais falhas)
+func (r Result) State() string {
+	var dnsErr *net.DNSError
+	switch {
+	case r.Open:
+		return StateOpen
+	case errors.As(r.Err, &dnsErr):
+		return StateError
+	case r.TimedOut:
+		return StateFiltered
+	case isRefused(r.Err):
+		return StateClosed
+	default:
+		return StateError
+	}
+}
+
+// ParsePorts interpreta uma lista de portas e faixas separadas por vírgula, como
+// "8000-8100,5432", sem repetições e na ordem em que aparecem
+func ParsePorts(spec string) ([]int, error) {
+	var ports []int
+	seen := make(map[int]bool)
+	for _, item := range strings.Split(spec, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		from, to, err := ParseRange(item)
+		if err != nil {
+			return nil, err
+		}
+		for p := from; p <= to; p++ {
+			if !seen[p] {
+				seen[p] = true
+				ports = append(ports, p)
+			}
+		}
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("nenhuma porta informada em '%s'", spec)
+	}
+	return ports, nil
+}
+
+// ExpandHosts interpreta uma lista de hosts separados por vírgula; blocos CIDR
+// (ex.: 10.0.0.0/30) são expandidos nos endereços de host, sem os endereços de
+// rede e broadcast em blocos IPv4 com mais de dois endereços
+func ExpandHosts(spec string) ([]string, error) {
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		expanded := []string{item}
+		if strings.Contains(item, "/") {
+			var err error
+			if expanded, err = expandCIDR(item); err != nil {
+				return nil, err
+			}
+		}
+		for _, host := range expanded {
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("nenhum host informado em '%s'", spec)
+	}
+	return hosts, nil
+}
+
+// expandCIDR lista os endereços de host de um bloco CIDR
+func expandCIDR(cidr string) ([]string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("bloco CIDR inválido '%s'", cidr)
+	}
+	prefix = prefix.Masked()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 {
+		return nil, fmt.Errorf("bloco CIDR '%s' grande demais: o limite é de %d endereços", cidr, maxCIDRHosts)
+	}
+
+	var hosts []string
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		hosts = append(hosts, addr.String())
+		if !addr.Next().IsValid() {
+			break
+		}
+	}
+	// Endereços de rede e broadcast não são hosts em blocos IPv4 maiores que /31
+	if prefix.Addr().Is4() && hostBits > 1 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	return hosts, nil
+}
+
+// ReadHostsFile lê hosts de um arquivo, um por linha (ou separados por vírgula),
+// ignorando linhas vazias e comentários iniciados por '#'
+func ReadHostsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de hosts: %w", err)
+	}
+	defer f.Close()
+
+	var items []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de hosts: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("arquivo de hosts %s vazio", path)
+	}
+	return ExpandHosts(strings.Join(items, ","))
+}
+
+// Targets combina hosts e portas, respeitando MaxTargets
+func Targets(hosts []string, ports []int) ([]Target, error) {
+	if total := len(hosts) * len(ports); total > MaxTargets {
+		return nil, fmt.Errorf("%d combinações de host e porta excedem o limite de %d", total, MaxTargets)
+	}
+	targets := make([]Target, 0, len(hosts)*len(ports))
+	for _, host := range hosts {
+		for _, port := range ports {
+			targets = append(targets, Target{Host: host, Port: port})
+		}
+	}
+	return targets, nil
+}
+
+// Scan verifica os alvos com até concurrency conexões simultâneas e retorna os
+// resultados ordenados por host e porta
+func Scan(ctx context.Context, targets []Target, concurrency int, timeout time.Duration) []Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]Result, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(targets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = Probe(ctx, targets[i].Host, targets[i].Port, timeout)
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	SortResults(results)
+	return results
+}
+
+// SortResults ordena por host (endereços IP em ordem numérica, antes dos nomes)
+// e porta
+func SortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Host != results[j].Host {
+			return hostLess(results[i].Host, results[j].Host)
+		}
+		return results[i].Port < results[j].Port
+	})
+}
+
+// hostLess compara hosts: IPs numericamente, depois nomes em ordem alfabética
+func hostLess(a, b string) bool {
+	ipA, errA := netip.ParseAddr(a)
+	ipB, errB := netip.ParseAddr(b)
+	switch {
+	case errA == nil && errB == nil:
+		return ipA.Less(ipB)
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	default:
+		return a < b
+	}
+}
@@ -0,0 +1,144 @@
+package portcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePorts(t *testing.T) {
+	ports, err := ParsePorts("8000-8003,5432, 8001")
+	require.NoError(t, err)
+	assert.Equal(t, []int{8000, 8001, 8002, 8003, 5432}, ports)
+
+	for _, spec := range []string{"", ",", "80,abc", "0", "100-90"} {
+		_, err := ParsePorts(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestExpandHosts(t *testing.T) {
+	hosts, err := ExpandHosts("10.0.0.0/30,db.local,10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "db.local"}, hosts)
+
+	hosts, err = ExpandHosts("192.168.1.7/31,192.168.1.9/32")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.6", "192.168.1.7", "192.168.1.9"}, hosts)
+
+	hosts, err = ExpandHosts("fd00::/127")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fd00::", "fd00::1"}, hosts)
+
+	hosts, err = ExpandHosts("10.0.0.0/16")
+	require.NoError(t, err)
+	assert.Len(t, hosts, 65534)
+
+	for _, spec := range []string{"", "10.0.0.0/33", "10.0.0.0/8", "abc/24"} {
+		_, err := ExpandHosts(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestReadHostsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# servidores\nweb.local\n\n10.1.0.0/30 # rede\ndb.local, cache.local\n"), 0644))
+	hosts, err := ReadHostsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web.local", "10.1.0.1", "10.1.0.2", "db.local", "cache.local"}, hosts)
+
+	require.NoError(t, os.WriteFile(path, []byte("# vazio\n"), 0644))
+	_, err = ReadHostsFile(path)
+	assert.Error(t, err)
+}
+
+func TestTargets(t *testing.T) {
+	targets, err := Targets([]string{"a", "b"}, []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{"a", 1}, {"a", 2}, {"b", 1}, {"b", 2}}, targets)
+
+	_, err = Targets(make([]string, 1000), make([]int, 2000))
+	assert.Error(t, err)
+}
+
+// listen abre um listener local que aceita e fecha as conexões
+func listen(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort retorna uma porta local sem listener
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+	return port
+}
+
+func TestScan(t *testing.T) {
+	open1, open2, closed := listen(t), listen(t), closedPort(t)
+	targets, err := Targets([]string{"localhost", "127.0.0.1"}, []int{open2, closed, open1})
+	require.NoError(t, err)
+
+	results := Scan(context.Background(), targets, 2, time.Second)
+	require.Len(t, results, 6)
+
+	// IPs antes dos nomes e portas em ordem crescente
+	assert.Equal(t, "127.0.0.1", results[0].Host)
+	assert.Equal(t, "localhost", results[5].Host)
+	for i := 1; i < 3; i++ {
+		assert.Less(t, results[i-1].Port, results[i].Port)
+	}
+
+	states := make(map[int]string)
+	for _, r := range results[:3] {
+		states[r.Port] = r.State()
+	}
+	assert.Equal(t, map[int]string{open1: StateOpen, open2: StateOpen, closed: StateClosed}, states)
+}
+
+func TestSortResults(t *testing.T) {
+	results := []Result{{Host: "db.local", Port: 1}, {Host: "10.0.0.10", Port: 1}, {Host: "10.0.0.9", Port: 2}, {Host: "10.0.0.9", Port: 1}}
+	SortResults(results)
+	var got []string
+	for _, r := range results {
+		got = append(got, net.JoinHostPort(r.Host, string(rune('0'+r.Port))))
+	}
+	assert.Equal(t, []string{"10.0.0.9:1", "10.0.0.9:2", "10.0.0.10:1", "db.local:1"}, got)
+}
+
+func TestResultState(t *testing.T) {
+	assert.Equal(t, StateOpen, Result{Open: true}.State())
+	assert.Equal(t, StateFiltered, Result{TimedOut: true, Err: errors.New("i/o timeout")}.State())
+	// Timeouts de DNS não indicam filtragem da porta
+	assert.Equal(t, StateError, Result{TimedOut: true, Err: &net.DNSError{Err: "timeout", IsTimeout: true}}.State())
+	assert.Equal(t, StateError, Result{Err: errors.New("network is unreachable")}.State())
+
+	closed := Probe(context.Background(), "127.0.0.1", closedPort(t), time.Second)
+	assert.Equal(t, StateClosed, closed.State())
+
+	// O domínio .invalid nunca resolve
+	unresolved := Probe(context.Background(), "bast-teste.invalid", 80, time.Second)
+	assert.Equal(t, StateError, unresolved.State())
+}
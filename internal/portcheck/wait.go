@@ -0,0 +1,117 @@
+package portcheck
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+)
+
+// WaitOptions configura a espera de Wait
+type WaitOptions struct {
+	// Closed espera os alvos deixarem de aceitar conexões, em vez de passarem a aceitar
+	Closed bool
+	// Interval intervalo inicial entre rodadas, dobrado a cada rodada até MaxInterval
+	Interval    time.Duration
+	MaxInterval time.Duration
+	// DialTimeout timeout de cada tentativa de conexão
+	DialTimeout time.Duration
+
+	// OnReady é chamado, se definido, quando um alvo atinge o estado esperado
+	OnReady func(r Result, elapsed time.Duration)
+	// OnRetry é chamado, se definido, ao fim de cada rodada com alvos pendentes
+	OnRetry func(pending []Result, attempt int, next time.Duration)
+}
+
+// ParseTarget interpreta um alvo "host:porta" ("[::1]:80" em IPv6); sem host,
+// como em "8080" ou ":8080", usa defaultHost
+func ParseTarget(spec, defaultHost string) (Target, error) {
+	host, portStr, err := net.SplitHostPort(spec)
+	if err != nil {
+		if _, convErr := strconv.Atoi(spec); convErr != nil {
+			return Target{}, fmt.Errorf("alvo inválido '%s': use host:porta, ex.: db:5432", spec)
+		}
+		host, portStr = "", spec
+	}
+	if host == "" {
+		host = defaultHost
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < constants.MinPort || port > constants.MaxPort {
+		return Target{}, fmt.Errorf("porta inválida em '%s': "+constants.ErrInvalidPort, spec, constants.MinPort, constants.MaxPort)
+	}
+	return Target{Host: host, Port: port}, nil
+}
+
+// Wait verifica os alvos em rodadas até que todos aceitem conexões (ou, com
+// Closed, até que todos deixem de aceitar) ou que ctx termine. Cada alvo deixa
+// de ser verificado ao atingir o estado esperado; entre as rodadas, a espera
+// cresce exponencialmente, com variação aleatória para que vários processos
+// aguardando o mesmo serviço não tentem ao mesmo tempo. Retorna o último
+// resultado dos alvos pendentes e o erro de ctx, se a espera não terminou
+func Wait(ctx context.Context, targets []Target, opts WaitOptions) ([]Result, error) {
+	start := time.Now()
+	pending := targets
+	var failed []Result
+	for attempt := 1; ; attempt++ {
+		results := Scan(ctx, pending, len(pending), opts.DialTimeout)
+		if err := ctx.Err(); err != nil {
+			// Tentativas interrompidas não dizem nada sobre os alvos: vale a rodada anterior
+			if failed == nil {
+				failed = results
+			}
+			return failed, err
+		}
+
+		failed = nil
+		pending = pending[:0:0]
+		for _, r := range results {
+			if r.Open != opts.Closed {
+				if opts.OnReady != nil {
+					opts.OnReady(r, time.Since(start))
+				}
+				continue
+			}
+			failed = append(failed, r)
+			pending = append(pending, Target{Host: r.Host, Port: r.Port})
+		}
+		if len(pending) == 0 {
+			return nil, nil
+		}
+
+		next := Backoff(opts.Interval, opts.MaxInterval, attempt)
+		if opts.OnRetry != nil {
+			opts.OnRetry(failed, attempt, next)
+		}
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return failed, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff calcula a espera antes da próxima rodada: interval dobrado a cada
+// tentativa, limitado a max, sorteado entre a metade e o valor cheio
+func Backoff(interval, max time.Duration, attempt int) time.Duration {
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	if max < interval {
+		max = interval
+	}
+	d := interval
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d/2 + rand.N(d/2+1)
+}
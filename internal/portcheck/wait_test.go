@@ -0,0 +1,113 @@
+package portcheck
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	cases := map[string]Target{
+		"db:5432":     {Host: "db", Port: 5432},
+		"[::1]:80":    {Host: "::1", Port: 80},
+		":8080":       {Host: "localhost", Port: 8080},
+		"8080":        {Host: "localhost", Port: 8080},
+		"10.0.0.1:22": {Host: "10.0.0.1", Port: 22},
+	}
+	for spec, want := range cases {
+		got, err := ParseTarget(spec, "localhost")
+		require.NoError(t, err, spec)
+		assert.Equal(t, want, got, spec)
+	}
+
+	for _, invalid := range []string{"db", "db:http", "db:0", "db:70000", ""} {
+		_, err := ParseTarget(invalid, "localhost")
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, full := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		5:  time.Second,
+		50: time.Second,
+	} {
+		for i := 0; i < 20; i++ {
+			d := Backoff(100*time.Millisecond, time.Second, attempt)
+			assert.GreaterOrEqual(t, d, full/2, "tentativa %d", attempt)
+			assert.LessOrEqual(t, d, full, "tentativa %d", attempt)
+		}
+	}
+}
+
+func TestWaitOpen(t *testing.T) {
+	port := closedPort(t)
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { ln.Close() })
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var ready []Result
+	retries := 0
+	pending, err := Wait(ctx, []Target{{Host: "127.0.0.1", Port: port}}, WaitOptions{
+		Interval:    20 * time.Millisecond,
+		MaxInterval: 50 * time.Millisecond,
+		DialTimeout: time.Second,
+		OnReady:     func(r Result, _ time.Duration) { ready = append(ready, r) },
+		OnRetry:     func(_ []Result, _ int, _ time.Duration) { retries++ },
+	})
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	require.Len(t, ready, 1)
+	assert.Equal(t, port, ready[0].Port)
+	assert.Positive(t, retries)
+}
+
+func TestWaitClosed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	time.AfterFunc(100*time.Millisecond, func() { ln.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = Wait(ctx, []Target{{Host: "127.0.0.1", Port: port}}, WaitOptions{
+		Closed:      true,
+		Interval:    20 * time.Millisecond,
+		MaxInterval: 50 * time.Millisecond,
+		DialTimeout: time.Second,
+	})
+	assert.NoError(t, err)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	open := ln.Addr().(*net.TCPAddr).Port
+	closed := closedPort(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	pending, err := Wait(ctx, []Target{{Host: "127.0.0.1", Port: open}, {Host: "127.0.0.1", Port: closed}}, WaitOptions{
+		Interval:    20 * time.Millisecond,
+		MaxInterval: 50 * time.Millisecond,
+		DialTimeout: time.Second,
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Len(t, pending, 1)
+	assert.Equal(t, closed, pending[0].Port)
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash usado para comparar senhas de usuários inexistentes no mesmo tempo
+// de um usuário válido; gerado apenas quando necessário
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(""), bcrypt.DefaultCost)
+	return hash
+})
+
+// Auth aplica restrição por IP e autenticação basic/bearer às requisições
+type Auth struct {
+	realm  string
+	users  map[string][]byte
+	tokens [][32]byte
+	allow  []netip.Prefix
+	deny   []netip.Prefix
+	exempt []string
+	log    *logrus.Logger
+
+	// verified guarda o SHA-256 da última senha aceita de cada usuário,
+	// evitando repetir o bcrypt a cada requisição
+	mu       sync.Mutex
+	verified map[string][32]byte
+}
+
+// NewAuth valida a configuração e cria o middleware de autenticação;
+// retorna nil se nada estiver configurado
+func NewAuth(cfg config.AuthConfig, log *logrus.Logger) (*Auth, error) {
+	if len(cfg.Users) == 0 && len(cfg.Tokens) == 0 && len(cfg.Allow) == 0 && len(cfg.Deny) == 0 {
+		return nil, nil
+	}
+
+	a := &Auth{
+		realm:    cfg.Realm,
+		users:    make(map[string][]byte),
+		exempt:   cfg.Exempt,
+		log:      log,
+		verified: make(map[string][32]byte),
+	}
+	for _, u := range cfg.Users {
+		if u.Username == "" {
+			return nil, fmt.Errorf("usuário sem username em server.auth.users")
+		}
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			return nil, fmt.Errorf("usuário %s: password_hash não é um hash bcrypt válido", u.Username)
+		}
+		a.users[u.Username] = []byte(u.PasswordHash)
+	}
+	for _, token := range cfg.Tokens {
+		if token == "" {
+			return nil, fmt.Errorf("token vazio em server.auth.tokens")
+		}
+		a.tokens = append(a.tokens, sha256.Sum256([]byte(token)))
+	}
+
+	var err error
+	if a.allow, err = parsePrefixes(cfg.Allow); err != nil {
+		return nil, err
+	}
+	if a.deny, err = parsePrefixes(cfg.Deny); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// parsePrefixes interpreta CIDRs; um IP sem máscara equivale a /32 ou /128
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("CIDR inválido '%s'", v)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("CIDR inválido '%s'", v)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Middleware retorna o middleware de autenticação; caminhos isentos passam sem verificação
+func (a *Auth) Middleware() Middleware {
+	if a == nil {
+		return nil
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if matchPath(a.exempt, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if reason := a.checkIP(r); reason != "" {
+				a.logDenied(r, reason, "")
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			if user, reason := a.checkCredentials(r); reason != "" {
+				a.logDenied(r, reason, user)
+				a.challenge(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// matchPath verifica se o caminho coincide com algum padrão; um "*" final
+// aceita qualquer caminho com o prefixo
+func matchPath(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIP aplica as listas deny e allow ao endereço remoto; retorna o motivo da recusa
+func (a *Auth) checkIP(r *http.Request) string {
+	if len(a.allow) == 0 && len(a.deny) == 0 {
+		return ""
+	}
+	addr, ok := remoteAddr(r)
+	if !ok {
+		return "endereço remoto inválido"
+	}
+	for _, p := range a.deny {
+		if p.Contains(addr) {
+			return "IP bloqueado por " + p.String()
+		}
+	}
+	if len(a.allow) == 0 {
+		return ""
+	}
+	for _, p := range a.allow {
+		if p.Contains(addr) {
+			return ""
+		}
+	}
+	return "IP fora da lista de permitidos"
+}
+
+// remoteAddr extrai o IP da conexão (cabeçalhos como X-Forwarded-For não são considerados)
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// checkCredentials valida basic auth ou bearer token; retorna o usuário informado
+// e o motivo da recusa
+func (a *Auth) checkCredentials(r *http.Request) (string, string) {
+	if len(a.users) == 0 && len(a.tokens) == 0 {
+		return "", ""
+	}
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", "credenciais ausentes"
+	}
+
+	if token, ok := cutPrefixFold(header, "Bearer "); ok && len(a.tokens) > 0 {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+		valid := 0
+		for _, t := range a.tokens {
+			valid |= subtle.ConstantTimeCompare(sum[:], t[:])
+		}
+		if valid == 1 {
+			return "", ""
+		}
+		return "", "token inválido"
+	}
+
+	if user, pass, ok := r.BasicAuth(); ok && len(a.users) > 0 {
+		if a.verifyPassword(user, pass) {
+			return user, ""
+		}
+		return user, "usuário ou senha inválidos"
+	}
+	return "", "esquema de autenticação não aceito"
+}
+
+// verifyPassword compara a senha com o hash bcrypt do usuário
+func (a *Auth) verifyPassword(user, pass string) bool {
+	sum := sha256.Sum256([]byte(pass))
+	a.mu.Lock()
+	cached, ok := a.verified[user]
+	a.mu.Unlock()
+	if ok && subtle.ConstantTimeCompare(sum[:], cached[:]) == 1 {
+		return true
+	}
+
+	hash, exists := a.users[user]
+	if !exists {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(pass))
+		return false
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(pass)) != nil {
+		return false
+	}
+	a.mu.Lock()
+	a.verified[user] = sum
+	a.mu.Unlock()
+	return true
+}
+
+// challenge responde 401 indicando os esquemas aceitos
+func (a *Auth) challenge(w http.ResponseWriter) {
+	if len(a.users) > 0 {
+		w.Header().Add("WWW-Authenticate", fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", a.realm))
+	}
+	if len(a.tokens) > 0 {
+		w.Header().Add("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", a.realm))
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// logDenied registra a tentativa recusada no logger estruturado
+func (a *Auth) logDenied(r *http.Request, reason, user string) {
+	if a.log == nil {
+		return
+	}
+	fields := logrus.Fields{
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"remote_addr": r.RemoteAddr,
+		"reason":      reason,
+		"request_id":  RequestIDFromContext(r.Context()),
+	}
+	if user != "" {
+		fields["user"] = user
+	}
+	a.log.WithFields(fields).Warn("acesso negado")
+}
+
+// cutPrefixFold é como strings.CutPrefix, ignorando maiúsculas no prefixo
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func authRequest(h http.Handler, path, remote string, prepare func(*http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remote
+	if prepare != nil {
+		prepare(req)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthCredentials(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("segredo"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+
+	auth, err := NewAuth(config.AuthConfig{
+		Realm:  "bast",
+		Users:  []config.AuthUser{{Username: "maria", PasswordHash: string(hash)}},
+		Tokens: []string{"tok"},
+		Exempt: []string{"/health", "/publico/*"},
+	}, log)
+	require.NoError(t, err)
+	h := Chain(teapot(), auth.Middleware())
+	const remote = "192.0.2.1:1234"
+
+	rec := authRequest(h, "/", remote, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Len(t, rec.Header().Values("WWW-Authenticate"), 2)
+	assert.Contains(t, buf.String(), "credenciais ausentes")
+
+	ok := func(r *http.Request) { r.SetBasicAuth("maria", "segredo") }
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/", remote, ok).Code)
+	// Segunda requisição usa a verificação em cache
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/", remote, ok).Code)
+
+	buf.Reset()
+	rec = authRequest(h, "/", remote, func(r *http.Request) { r.SetBasicAuth("maria", "errada") })
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, buf.String(), "user=maria")
+
+	assert.Equal(t, http.StatusUnauthorized, authRequest(h, "/", remote, func(r *http.Request) { r.SetBasicAuth("joao", "segredo") }).Code)
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/", remote, func(r *http.Request) { r.Header.Set("Authorization", "bearer tok") }).Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(h, "/", remote, func(r *http.Request) { r.Header.Set("Authorization", "Bearer outro") }).Code)
+
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/health", remote, nil).Code)
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/publico/a.css", remote, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, authRequest(h, "/health/extra", remote, nil).Code)
+}
+
+func TestAuthIPLists(t *testing.T) {
+	auth, err := NewAuth(config.AuthConfig{
+		Allow: []string{"10.0.0.0/8", "::1"},
+		Deny:  []string{"10.0.0.5"},
+	}, nil)
+	require.NoError(t, err)
+	h := Chain(teapot(), auth.Middleware())
+
+	tests := []struct {
+		remote string
+		want   int
+	}{
+		{"10.1.2.3:1000", http.StatusTeapot},
+		{"[::1]:1000", http.StatusTeapot},
+		{"[::ffff:10.1.2.3]:1000", http.StatusTeapot},
+		{"10.0.0.5:1000", http.StatusForbidden},
+		{"192.168.0.1:1000", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, authRequest(h, "/", tt.remote, nil).Code, tt.remote)
+	}
+}
+
+func TestNewAuth(t *testing.T) {
+	auth, err := NewAuth(config.AuthConfig{Exempt: []string{"/health"}}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, auth)
+	assert.Nil(t, auth.Middleware())
+
+	invalid := []config.AuthConfig{
+		{Users: []config.AuthUser{{Username: "maria", PasswordHash: "texto-puro"}}},
+		{Users: []config.AuthUser{{PasswordHash: "$2a$10$"}}},
+		{Tokens: []string{""}},
+		{Allow: []string{"10.0.0.0/40"}},
+		{Deny: []string{"rede"}},
+	}
+	for _, cfg := range invalid {
+		_, err := NewAuth(cfg, nil)
+		assert.Error(t, err)
+	}
+}
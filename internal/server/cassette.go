@@ -0,0 +1,416 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// CassetteHeader cabeçalho que indica se a resposta foi gravada ou reproduzida
+const CassetteHeader = "X-Bast-Cassette"
+
+// Critérios de correspondência entre requisição e gravação
+const (
+	MatchMethod = "method"
+	MatchPath   = "path"
+	MatchQuery  = "query"
+	MatchBody   = "body"
+	MatchHeader = "header:" // prefixo seguido do nome do cabeçalho
+)
+
+// DefaultMatch critérios usados quando nenhum é informado
+var DefaultMatch = []string{MatchMethod, MatchPath, MatchQuery}
+
+// secretHeaders cabeçalhos gravados apenas como hash SHA-256
+var secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// cassetteName remove do nome do arquivo o que não for seguro em qualquer sistema
+var cassetteName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Interaction par de requisição e resposta gravado em disco
+type Interaction struct {
+	RecordedAt time.Time        `json:"recorded_at"`
+	DurationMS float64          `json:"duration_ms"`
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	file       string           // arquivo de origem, para mensagens
+}
+
+// RecordedRequest requisição gravada
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	Path         string      `json:"path"`
+	Query        string      `json:"query,omitempty"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"` // "base64" para corpos binários
+	BodySHA256   string      `json:"body_sha256"`
+}
+
+// RecordedResponse resposta gravada
+type RecordedResponse struct {
+	Status       int         `json:"status"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// MatchOptions define quais partes da requisição identificam uma gravação
+type MatchOptions struct {
+	Method  bool
+	Path    bool
+	Query   bool
+	Body    bool
+	Headers []string // nomes canônicos
+}
+
+// ParseMatch interpreta critérios como method, path, query, body e header:Nome
+func ParseMatch(criteria []string) (MatchOptions, error) {
+	if len(criteria) == 0 {
+		criteria = DefaultMatch
+	}
+	var m MatchOptions
+	for _, c := range criteria {
+		c = strings.TrimSpace(c)
+		switch strings.ToLower(c) {
+		case MatchMethod:
+			m.Method = true
+		case MatchPath:
+			m.Path = true
+		case MatchQuery:
+			m.Query = true
+		case MatchBody:
+			m.Body = true
+		default:
+			name, ok := cutPrefixFold(c, MatchHeader)
+			if !ok || strings.TrimSpace(name) == "" {
+				return MatchOptions{}, fmt.Errorf("critério de correspondência inválido '%s': use method, path, query, body ou header:Nome", c)
+			}
+			m.Headers = append(m.Headers, http.CanonicalHeaderKey(strings.TrimSpace(name)))
+		}
+	}
+	sort.Strings(m.Headers)
+	return m, nil
+}
+
+// key monta a chave de correspondência de uma requisição gravada
+func (m MatchOptions) key(req RecordedRequest) string {
+	var parts []string
+	if m.Method {
+		parts = append(parts, strings.ToUpper(req.Method))
+	}
+	if m.Path {
+		parts = append(parts, req.Path)
+	}
+	if m.Query {
+		values, _ := url.ParseQuery(req.Query)
+		parts = append(parts, values.Encode())
+	}
+	if m.Body {
+		parts = append(parts, req.BodySHA256)
+	}
+	for _, name := range m.Headers {
+		parts = append(parts, name+"="+strings.Join(req.Headers.Values(name), ","))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// recordRequest converte a requisição para o formato gravado, lendo o corpo e
+// substituindo-o por uma cópia para quem vier depois
+func recordRequest(r *http.Request) (RecordedRequest, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return RecordedRequest{}, err
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	headers := r.Header.Clone()
+	for _, name := range secretHeaders {
+		for i, v := range headers.Values(name) {
+			headers[name][i] = hashValue(v)
+		}
+	}
+	sum := sha256.Sum256(body)
+	encoded, encoding := encodeBody(body)
+	return RecordedRequest{
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		Query:        r.URL.RawQuery,
+		Headers:      headers,
+		Body:         encoded,
+		BodyEncoding: encoding,
+		BodySHA256:   hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// hashValue representa um valor sensível pelo seu hash
+func hashValue(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// encodeBody guarda corpos UTF-8 como texto e os demais em base64
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+// decodeBody desfaz encodeBody
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+// interactionKey chave de contexto da interação em gravação
+type interactionKey struct{}
+
+// Recorder repassa as requisições a um upstream e grava cada par de requisição e
+// resposta como um arquivo JSON no diretório do cassete
+type Recorder struct {
+	// OnRecord é chamado, se definido, após cada tentativa de gravação
+	OnRecord func(file string, in *Interaction, err error)
+
+	upstream *url.URL
+	dir      string
+	proxy    *httputil.ReverseProxy
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecorder cria o gravador; o diretório é criado se não existir e a numeração
+// continua a partir das gravações já existentes
+func NewRecorder(upstream, dir string) (*Recorder, error) {
+	target, err := url.Parse(upstream)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("URL de upstream inválida '%s'", upstream)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do cassete: %w", err)
+	}
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &Recorder{upstream: target, dir: dir, seq: len(files)}
+	rec.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+		},
+		ModifyResponse: rec.capture,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("erro ao acessar upstream %s: %v", target.Host, err), http.StatusBadGateway)
+		},
+	}
+	return rec, nil
+}
+
+// Upstream retorna a URL do upstream gravado
+func (rec *Recorder) Upstream() *url.URL {
+	return rec.upstream
+}
+
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := recordRequest(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("erro ao ler requisição: %v", err), http.StatusBadRequest)
+		return
+	}
+	in := &Interaction{RecordedAt: time.Now(), Request: req}
+	start := time.Now()
+	w.Header().Set(CassetteHeader, "recorded")
+	rec.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), interactionKey{}, in)))
+	in.DurationMS = durationMS(time.Since(start))
+
+	if in.Response.Status == 0 {
+		// Upstream indisponível: nada a gravar
+		return
+	}
+	file, err := rec.save(in)
+	if rec.OnRecord != nil {
+		rec.OnRecord(file, in, err)
+	}
+}
+
+// capture lê a resposta do upstream para gravá-la e a devolve intacta ao cliente
+func (rec *Recorder) capture(resp *http.Response) error {
+	in, ok := resp.Request.Context().Value(interactionKey{}).(*Interaction)
+	if !ok {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	headers := resp.Header.Clone()
+	headers.Del(CassetteHeader)
+	encoded, encoding := encodeBody(body)
+	in.Response = RecordedResponse{Status: resp.StatusCode, Headers: headers, Body: encoded, BodyEncoding: encoding}
+	return nil
+}
+
+// save grava a interação em <seq>-<método>-<caminho>.json
+func (rec *Recorder) save(in *Interaction) (string, error) {
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.seq++
+	slug := strings.Trim(cassetteName.ReplaceAllString(in.Request.Path, "-"), "-")
+	if slug == "" {
+		slug = "root"
+	}
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	file := filepath.Join(rec.dir, fmt.Sprintf("%04d-%s-%s.json", rec.seq, in.Request.Method, slug))
+	return file, os.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// cassetteFiles lista os arquivos JSON do cassete em ordem de gravação
+func cassetteFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return cassetteSeq(files[i]) < cassetteSeq(files[j])
+	})
+	return files, nil
+}
+
+// cassetteSeq extrai o número de sequência do nome do arquivo
+func cassetteSeq(file string) int {
+	prefix, _, _ := strings.Cut(filepath.Base(file), "-")
+	n, _ := strconv.Atoi(prefix)
+	return n
+}
+
+// Replayer responde a partir das gravações de um cassete; gravações que
+// correspondem à mesma chave são servidas em ordem e a última se repete
+type Replayer struct {
+	match MatchOptions
+
+	mu      sync.Mutex
+	entries map[string][]*Interaction
+	next    map[string]int
+	total   int
+}
+
+// LoadCassette carrega todas as gravações do diretório
+func LoadCassette(dir string, match MatchOptions) (*Replayer, error) {
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nenhuma gravação encontrada em %s", dir)
+	}
+
+	p := &Replayer{match: match, entries: make(map[string][]*Interaction), next: make(map[string]int)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		in := &Interaction{file: file}
+		if err := json.Unmarshal(data, in); err != nil {
+			return nil, fmt.Errorf("gravação inválida %s: %w", filepath.Base(file), err)
+		}
+		if in.Request.Method == "" || in.Response.Status == 0 {
+			return nil, fmt.Errorf("gravação inválida %s: method e status são obrigatórios", filepath.Base(file))
+		}
+		key := match.key(in.Request)
+		p.entries[key] = append(p.entries[key], in)
+		p.total++
+	}
+	return p, nil
+}
+
+// Len retorna o número de gravações carregadas
+func (p *Replayer) Len() int {
+	return p.total
+}
+
+// lookup retorna a próxima gravação correspondente à requisição
+func (p *Replayer) lookup(req RecordedRequest) *Interaction {
+	key := p.match.key(req)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := p.entries[key]
+	if len(entries) == 0 {
+		return nil
+	}
+	i := p.next[key]
+	if i < len(entries)-1 {
+		p.next[key] = i + 1
+	}
+	return entries[i]
+}
+
+func (p *Replayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := recordRequest(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("erro ao ler requisição: %v", err), http.StatusBadRequest)
+		return
+	}
+	in := p.lookup(req)
+	if in == nil {
+		w.Header().Set(CassetteHeader, "miss")
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error":  "nenhuma gravação corresponde à requisição",
+			"method": req.Method,
+			"path":   req.Path,
+		})
+		return
+	}
+
+	body, err := decodeBody(in.Response.Body, in.Response.BodyEncoding)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("gravação inválida %s: %v", filepath.Base(in.file), err), http.StatusInternalServerError)
+		return
+	}
+	h := w.Header()
+	for name, values := range in.Response.Headers {
+		h[name] = values
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	h.Set(CassetteHeader, "replayed; "+filepath.Base(in.file))
+	w.WriteHeader(in.Response.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingUpstream responde com um contador, método, caminho, query, corpo e
+// se o token esperado foi recebido
+func newCountingUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		n := calls.Add(1)
+		if r.URL.Path == "/binario" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte{0xff, 0x00, 0xfe, byte(n)})
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Upstream", "sim")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%d %s %s ?%s body=%s auth=%t", n, r.Method, r.URL.Path, r.URL.RawQuery, body, r.Header.Get("Authorization") == "Bearer segredo")
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func doRequest(t *testing.T, h http.Handler, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func recordCassette(t *testing.T, dir string) {
+	t.Helper()
+	rec, err := NewRecorder(newCountingUpstream(t).URL, dir)
+	require.NoError(t, err)
+	var saved []string
+	rec.OnRecord = func(file string, in *Interaction, err error) {
+		require.NoError(t, err)
+		saved = append(saved, filepath.Base(file))
+	}
+
+	resp := doRequest(t, rec, http.MethodGet, "/users?a=1&b=2", "", nil)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "recorded", resp.Header().Get(CassetteHeader))
+	assert.Equal(t, "1 GET /users ?a=1&b=2 body= auth=false", resp.Body.String())
+
+	doRequest(t, rec, http.MethodGet, "/users?a=1&b=2", "", nil)
+	doRequest(t, rec, http.MethodPost, "/users", `{"nome":"ana"}`, nil)
+	doRequest(t, rec, http.MethodPost, "/users", `{"nome":"bia"}`, nil)
+	doRequest(t, rec, http.MethodGet, "/perfil", "", http.Header{"Authorization": {"Bearer segredo"}})
+	doRequest(t, rec, http.MethodGet, "/binario", "", nil)
+
+	assert.Equal(t, []string{
+		"0001-GET-users.json", "0002-GET-users.json", "0003-POST-users.json",
+		"0004-POST-users.json", "0005-GET-perfil.json", "0006-GET-binario.json",
+	}, saved)
+}
+
+func TestRecorderWritesInteractions(t *testing.T) {
+	dir := t.TempDir()
+	recordCassette(t, dir)
+
+	data, err := os.ReadFile(filepath.Join(dir, "0005-GET-perfil.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Bearer segredo", "credenciais não devem ser gravadas em texto")
+	var in Interaction
+	require.NoError(t, json.Unmarshal(data, &in))
+	assert.Equal(t, hashValue("Bearer segredo"), in.Request.Headers.Get("Authorization"))
+	assert.Equal(t, http.StatusCreated, in.Response.Status)
+	assert.Equal(t, "sim", in.Response.Headers.Get("X-Upstream"))
+
+	data, err = os.ReadFile(filepath.Join(dir, "0006-GET-binario.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &in))
+	assert.Equal(t, "base64", in.Response.BodyEncoding)
+
+	// Uma nova gravação continua a numeração existente
+	rec, err := NewRecorder(newCountingUpstream(t).URL, dir)
+	require.NoError(t, err)
+	var file string
+	rec.OnRecord = func(f string, in *Interaction, err error) { file = f }
+	doRequest(t, rec, http.MethodGet, "/", "", nil)
+	assert.Equal(t, "0007-GET-root.json", filepath.Base(file))
+
+	_, err = NewRecorder("ftp://exemplo", dir)
+	assert.Error(t, err)
+}
+
+func TestReplayDefaultMatch(t *testing.T) {
+	dir := t.TempDir()
+	recordCassette(t, dir)
+
+	p, err := LoadCassette(dir, MatchOptions{Method: true, Path: true, Query: true})
+	require.NoError(t, err)
+	assert.Equal(t, 6, p.Len())
+
+	// A ordem dos parâmetros não importa; gravações repetidas saem em ordem e a última se repete
+	first := doRequest(t, p, http.MethodGet, "/users?b=2&a=1", "", nil)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, "text/plain", first.Header().Get("Content-Type"))
+	assert.Equal(t, "replayed; 0001-GET-users.json", first.Header().Get(CassetteHeader))
+	assert.True(t, strings.HasPrefix(first.Body.String(), "1 GET"))
+	assert.True(t, strings.HasPrefix(doRequest(t, p, http.MethodGet, "/users?b=2&a=1", "", nil).Body.String(), "2 GET"))
+	assert.True(t, strings.HasPrefix(doRequest(t, p, http.MethodGet, "/users?b=2&a=1", "", nil).Body.String(), "2 GET"))
+
+	// Sem body no critério, qualquer corpo corresponde às gravações do POST
+	assert.Contains(t, doRequest(t, p, http.MethodPost, "/users", "outro", nil).Body.String(), "ana")
+
+	miss := doRequest(t, p, http.MethodGet, "/users?a=3", "", nil)
+	assert.Equal(t, http.StatusNotFound, miss.Code)
+	assert.Equal(t, "miss", miss.Header().Get(CassetteHeader))
+	assert.Equal(t, http.StatusNotFound, doRequest(t, p, http.MethodDelete, "/users?a=1&b=2", "", nil).Code)
+
+	bin := doRequest(t, p, http.MethodGet, "/binario", "", nil)
+	assert.Equal(t, []byte{0xff, 0x00, 0xfe, 6}, bin.Body.Bytes())
+}
+
+func TestReplayBodyAndHeaderMatch(t *testing.T) {
+	dir := t.TempDir()
+	recordCassette(t, dir)
+
+	match, err := ParseMatch([]string{"method", "path", "body", "header:authorization"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Authorization"}, match.Headers)
+	p, err := LoadCassette(dir, match)
+	require.NoError(t, err)
+
+	assert.Contains(t, doRequest(t, p, http.MethodPost, "/users", `{"nome":"bia"}`, nil).Body.String(), "bia")
+	assert.Contains(t, doRequest(t, p, http.MethodPost, "/users", `{"nome":"ana"}`, nil).Body.String(), "ana")
+	assert.Equal(t, http.StatusNotFound, doRequest(t, p, http.MethodPost, "/users", `{"nome":"eva"}`, nil).Code)
+
+	// Cabeçalhos sensíveis correspondem pelo hash
+	ok := doRequest(t, p, http.MethodGet, "/perfil", "", http.Header{"Authorization": {"Bearer segredo"}})
+	assert.Equal(t, http.StatusCreated, ok.Code)
+	assert.Contains(t, ok.Body.String(), "auth=true")
+	assert.Equal(t, http.StatusNotFound,
+		doRequest(t, p, http.MethodGet, "/perfil", "", http.Header{"Authorization": {"Bearer outro"}}).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(t, p, http.MethodGet, "/perfil", "", nil).Code)
+}
+
+func TestParseMatchAndLoadErrors(t *testing.T) {
+	m, err := ParseMatch(nil)
+	require.NoError(t, err)
+	assert.Equal(t, MatchOptions{Method: true, Path: true, Query: true}, m)
+
+	for _, invalid := range []string{"cookie", "header:", ""} {
+		_, err := ParseMatch([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+
+	_, err = LoadCassette(t.TempDir(), m)
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001-GET-x.json"), []byte("{"), 0644))
+	_, err = LoadCassette(dir, m)
+	assert.Error(t, err)
+}
@@ -0,0 +1,354 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+)
+
+// Distribuições de latência da injeção de falhas
+const (
+	LatencyFixed   = "fixed"
+	LatencyUniform = "uniform"
+	LatencyNormal  = "normal"
+)
+
+// ChaosHeader cabeçalho que descreve as falhas injetadas na resposta
+const ChaosHeader = "X-Bast-Chaos"
+
+// defaultChaosCodes status sorteados quando error_codes não é informado
+var defaultChaosCodes = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+
+// latency distribuição de latência: fixed usa a; uniform sorteia entre a e b;
+// normal usa média a e desvio padrão b
+type latency struct {
+	dist string
+	a, b time.Duration
+}
+
+// parseLatency interpreta "100ms", "fixed:100ms", "uniform:50ms,200ms" ou "normal:100ms,20ms"
+func parseLatency(spec string) (latency, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return latency{}, nil
+	}
+	dist, params, found := strings.Cut(spec, ":")
+	if !found {
+		dist, params = LatencyFixed, spec
+	}
+	dist = strings.ToLower(dist)
+
+	var values []time.Duration
+	for _, p := range strings.Split(params, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(p))
+		if err != nil {
+			return latency{}, fmt.Errorf("latência inválida '%s': %w", spec, err)
+		}
+		if d < 0 {
+			return latency{}, fmt.Errorf("latência inválida '%s': duração negativa", spec)
+		}
+		values = append(values, d)
+	}
+
+	switch {
+	case dist == LatencyFixed && len(values) == 1:
+		return latency{dist: dist, a: values[0]}, nil
+	case dist == LatencyUniform && len(values) == 2:
+		if values[1] < values[0] {
+			return latency{}, fmt.Errorf("latência inválida '%s': máximo menor que o mínimo", spec)
+		}
+		return latency{dist: dist, a: values[0], b: values[1]}, nil
+	case dist == LatencyNormal && len(values) == 2:
+		return latency{dist: dist, a: values[0], b: values[1]}, nil
+	}
+	return latency{}, fmt.Errorf("latência inválida '%s': use 100ms, uniform:50ms,200ms ou normal:100ms,20ms", spec)
+}
+
+// chaosRule regra compilada de config.ChaosRule
+type chaosRule struct {
+	path         string
+	latency      latency
+	errorRate    float64
+	codes        []int
+	resetRate    float64
+	truncateRate float64
+}
+
+// compileChaosRule valida a regra e aplica os padrões
+func compileChaosRule(rule config.ChaosRule) (chaosRule, error) {
+	lat, err := parseLatency(rule.Latency)
+	if err != nil {
+		return chaosRule{}, err
+	}
+	for name, rate := range map[string]float64{
+		"error_rate": rule.ErrorRate, "reset_rate": rule.ResetRate, "truncate_rate": rule.TruncateRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return chaosRule{}, fmt.Errorf("%s deve estar entre 0 e 1: %g", name, rate)
+		}
+	}
+	codes := rule.ErrorCodes
+	if len(codes) == 0 {
+		codes = defaultChaosCodes
+	}
+	for _, code := range codes {
+		if code < 400 || code > 599 {
+			return chaosRule{}, fmt.Errorf("status de erro inválido: %d", code)
+		}
+	}
+	return chaosRule{
+		path:         rule.Path,
+		latency:      lat,
+		errorRate:    rule.ErrorRate,
+		codes:        codes,
+		resetRate:    rule.ResetRate,
+		truncateRate: rule.TruncateRate,
+	}, nil
+}
+
+// chaosDecision falhas sorteadas para uma requisição
+type chaosDecision struct {
+	delay    time.Duration
+	status   int
+	reset    bool
+	truncate bool
+}
+
+// Chaos injeta latência, erros, conexões reiniciadas e corpos truncados; a
+// configuração pode ser trocada em tempo de execução por Update ou pelo Handler
+type Chaos struct {
+	mu     sync.Mutex
+	cfg    config.ChaosConfig
+	global chaosRule
+	routes []chaosRule
+	rng    *rand.Rand
+}
+
+// NewChaos cria o injetor de falhas com a configuração informada
+func NewChaos(cfg config.ChaosConfig) (*Chaos, error) {
+	c := &Chaos{}
+	if err := c.Update(cfg); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Update valida e aplica uma nova configuração; com seed diferente de zero, a
+// sequência de sorteios recomeça do início
+func (c *Chaos) Update(cfg config.ChaosConfig) error {
+	global, err := compileChaosRule(cfg.Global)
+	if err != nil {
+		return fmt.Errorf("regra global: %w", err)
+	}
+	routes := make([]chaosRule, 0, len(cfg.Routes))
+	for i, r := range cfg.Routes {
+		if r.Path == "" {
+			return fmt.Errorf("rota %d: path é obrigatório", i+1)
+		}
+		rule, err := compileChaosRule(r)
+		if err != nil {
+			return fmt.Errorf("rota %s: %w", r.Path, err)
+		}
+		routes = append(routes, rule)
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+	c.global = global
+	c.routes = routes
+	c.rng = rand.New(rand.NewSource(seed))
+	return nil
+}
+
+// Config retorna a configuração atual
+func (c *Chaos) Config() config.ChaosConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg
+}
+
+// decide sorteia as falhas da requisição; retorna false se o caminho não é afetado
+func (c *Chaos) decide(path string) (chaosDecision, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.cfg.Enabled || matchPath(c.cfg.Exempt, path) {
+		return chaosDecision{}, false
+	}
+	rule := c.global
+	for _, r := range c.routes {
+		if matchPath([]string{r.path}, path) {
+			rule = r
+			break
+		}
+	}
+
+	// Todos os sorteios acontecem sempre, na mesma ordem, para que a sequência
+	// dependa apenas da semente e do número de requisições
+	var d chaosDecision
+	switch rule.latency.dist {
+	case LatencyFixed:
+		d.delay = rule.latency.a
+	case LatencyUniform:
+		d.delay = rule.latency.a + time.Duration(c.rng.Int63n(int64(rule.latency.b-rule.latency.a)+1))
+	case LatencyNormal:
+		d.delay = max(0, rule.latency.a+time.Duration(c.rng.NormFloat64()*float64(rule.latency.b)))
+	}
+	errorDraw, code := c.rng.Float64(), rule.codes[c.rng.Intn(len(rule.codes))]
+	resetDraw, truncateDraw := c.rng.Float64(), c.rng.Float64()
+
+	switch {
+	case resetDraw < rule.resetRate:
+		d.reset = true
+	case errorDraw < rule.errorRate:
+		d.status = code
+	case truncateDraw < rule.truncateRate:
+		d.truncate = true
+	}
+	return d, true
+}
+
+// Middleware aplica as falhas sorteadas antes de chamar o handler; retorna nil se c for nil
+func (c *Chaos) Middleware() Middleware {
+	if c == nil {
+		return nil
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d, ok := c.decide(r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var injected []string
+			if d.delay > 0 {
+				injected = append(injected, "latency="+d.delay.Round(time.Millisecond).String())
+				select {
+				case <-time.After(d.delay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			switch {
+			case d.reset:
+				abortConnection(w, true)
+				return
+			case d.status != 0:
+				w.Header().Set(ChaosHeader, strings.Join(append(injected, "error="+strconv.Itoa(d.status)), ", "))
+				http.Error(w, "falha injetada pelo bast (chaos)", d.status)
+				return
+			case d.truncate:
+				w.Header().Set(ChaosHeader, strings.Join(append(injected, "truncate"), ", "))
+				serveTruncated(w, r, next)
+				return
+			}
+			if len(injected) > 0 {
+				w.Header().Set(ChaosHeader, strings.Join(injected, ", "))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// abortConnection fecha a conexão do cliente; com reset, descarta os dados
+// pendentes para que o cliente receba RST em vez de FIN
+func abortConnection(w http.ResponseWriter, reset bool) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// HTTP/2 e writers sem Hijack: o servidor interrompe o stream
+		panic(http.ErrAbortHandler)
+	}
+	if tlsConn, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = tlsConn.NetConn()
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok && reset {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
+
+// serveTruncated executa o handler e envia apenas metade do corpo, mantendo o
+// Content-Length completo, antes de fechar a conexão
+func serveTruncated(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	buf := &bufferedResponse{header: http.Header{}}
+	next.ServeHTTP(buf, r)
+
+	h := w.Header()
+	for name, values := range buf.header {
+		h[name] = values
+	}
+	h.Set("Content-Length", strconv.Itoa(len(buf.body)))
+	status := buf.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.body[:len(buf.body)/2])
+	_ = http.NewResponseController(w).Flush()
+	abortConnection(w, false)
+}
+
+// bufferedResponse ResponseWriter que guarda a resposta em memória
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	b.body = append(b.body, p...)
+	return len(p), nil
+}
+
+// Handler expõe a configuração atual (GET) e permite alterá-la (PUT); campos
+// omitidos no JSON mantêm o valor atual
+func (c *Chaos) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			writeJSON(w, http.StatusOK, c.Config())
+		case http.MethodPut:
+			cfg := c.Config()
+			dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&cfg); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "JSON inválido: " + err.Error()})
+				return
+			}
+			if err := c.Update(cfg); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, c.Config())
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
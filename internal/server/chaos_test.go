@@ -0,0 +1,160 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLatency(t *testing.T) {
+	valid := map[string]latency{
+		"":                  {},
+		"100ms":             {dist: LatencyFixed, a: 100 * time.Millisecond},
+		"fixed:1s":          {dist: LatencyFixed, a: time.Second},
+		"uniform:50ms,2s":   {dist: LatencyUniform, a: 50 * time.Millisecond, b: 2 * time.Second},
+		"normal:100ms,20ms": {dist: LatencyNormal, a: 100 * time.Millisecond, b: 20 * time.Millisecond},
+	}
+	for spec, want := range valid {
+		got, err := parseLatency(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, want, got, spec)
+	}
+
+	for _, spec := range []string{"rápido", "uniform:1s", "uniform:2s,1s", "normal:1s", "poisson:1s,2s", "-1s"} {
+		_, err := parseLatency(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestChaosDeterministic(t *testing.T) {
+	cfg := config.ChaosConfig{
+		Enabled: true,
+		Seed:    42,
+		Global:  config.ChaosRule{Latency: "uniform:0s,100ms", ErrorRate: 0.3, ResetRate: 0.1, TruncateRate: 0.1},
+	}
+	sequence := func() []chaosDecision {
+		c, err := NewChaos(cfg)
+		require.NoError(t, err)
+		var out []chaosDecision
+		for i := 0; i < 50; i++ {
+			d, ok := c.decide("/")
+			require.True(t, ok)
+			out = append(out, d)
+		}
+		return out
+	}
+
+	first := sequence()
+	assert.Equal(t, first, sequence())
+
+	var errs, resets, truncates int
+	for _, d := range first {
+		assert.True(t, d.delay >= 0 && d.delay <= 100*time.Millisecond)
+		if d.status != 0 {
+			errs++
+			assert.Contains(t, defaultChaosCodes, d.status)
+		}
+		if d.reset {
+			resets++
+		}
+		if d.truncate {
+			truncates++
+		}
+	}
+	assert.Positive(t, errs)
+	assert.Positive(t, resets)
+	assert.Positive(t, truncates)
+}
+
+func TestChaosRoutesAndExempt(t *testing.T) {
+	c, err := NewChaos(config.ChaosConfig{
+		Enabled: true,
+		Global:  config.ChaosRule{ErrorRate: 1, ErrorCodes: []int{503}},
+		Routes:  []config.ChaosRule{{Path: "/api/*", Latency: "5ms"}},
+		Exempt:  []string{"/health"},
+	})
+	require.NoError(t, err)
+	h := Chain(teapot(), c.Middleware())
+
+	rec := authRequest(h, "/", "192.0.2.1:1234", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "error=503", rec.Header().Get(ChaosHeader))
+
+	rec = authRequest(h, "/api/users", "192.0.2.1:1234", nil)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "latency=5ms", rec.Header().Get(ChaosHeader))
+
+	rec = authRequest(h, "/health", "192.0.2.1:1234", nil)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Empty(t, rec.Header().Get(ChaosHeader))
+
+	// Desabilitado em tempo de execução
+	require.NoError(t, c.Update(config.ChaosConfig{}))
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/", "192.0.2.1:1234", nil).Code)
+
+	_, err = NewChaos(config.ChaosConfig{Global: config.ChaosRule{ErrorRate: 2}})
+	assert.Error(t, err)
+	_, err = NewChaos(config.ChaosConfig{Global: config.ChaosRule{ErrorCodes: []int{200}}})
+	assert.Error(t, err)
+	_, err = NewChaos(config.ChaosConfig{Routes: []config.ChaosRule{{Latency: "1s"}}})
+	assert.Error(t, err)
+}
+
+func TestChaosResetAndTruncate(t *testing.T) {
+	c, err := NewChaos(config.ChaosConfig{Enabled: true, Global: config.ChaosRule{ResetRate: 1}})
+	require.NoError(t, err)
+	body := strings.Repeat("bast", 100)
+	srv := httptest.NewServer(Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}), c.Middleware()))
+	defer srv.Close()
+
+	_, err = http.Get(srv.URL)
+	assert.Error(t, err)
+
+	require.NoError(t, c.Update(config.ChaosConfig{Enabled: true, Global: config.ChaosRule{TruncateRate: 1}}))
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, int64(len(body)), resp.ContentLength)
+	assert.Equal(t, "truncate", resp.Header.Get(ChaosHeader))
+	got, err := io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Len(t, got, len(body)/2)
+}
+
+func TestChaosHandler(t *testing.T) {
+	c, err := NewChaos(config.ChaosConfig{Enabled: true, Seed: 1, Global: config.ChaosRule{Latency: "10ms"}})
+	require.NoError(t, err)
+	h := c.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__chaos", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"latency": "10ms"`)
+
+	// Campos omitidos mantêm o valor atual
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/__chaos", strings.NewReader(`{"global": {"error_rate": 0.5}}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	cfg := c.Config()
+	assert.Equal(t, "10ms", cfg.Global.Latency)
+	assert.Equal(t, 0.5, cfg.Global.ErrorRate)
+	assert.True(t, cfg.Enabled)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/__chaos", strings.NewReader(`{"global": {"latency": "rápido"}}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "10ms", c.Config().Global.Latency)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/__chaos", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
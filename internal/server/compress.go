@@ -0,0 +1,255 @@
+package server
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/andybalholm/brotli"
+)
+
+// Codificações de compressão suportadas
+const (
+	EncodingBrotli  = "br"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// incompressibleTypes prefixos de Content-Type que não se beneficiam de compressão
+var incompressibleTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/pdf",
+	"application/octet-stream", "text/event-stream",
+}
+
+// Compress comprime respostas com a codificação preferida entre as aceitas pelo
+// cliente; respostas menores que MinSize seguem sem compressão. Retorna nil se desabilitado
+func Compress(cfg config.CompressionConfig) (Middleware, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	for _, alg := range cfg.Algorithms {
+		if alg != EncodingBrotli && alg != EncodingGzip && alg != EncodingDeflate {
+			return nil, fmt.Errorf("algoritmo de compressão inválido '%s': use br, gzip ou deflate", alg)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.Algorithms)
+			// Range e upgrades (WebSocket) dependem dos bytes originais
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: cfg.MinSize}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}, nil
+}
+
+// negotiateEncoding escolhe o primeiro algoritmo da lista aceito pelo cliente (q > 0)
+func negotiateEncoding(acceptEncoding string, algorithms []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	accepted := make(map[string]bool)
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if name == "*" {
+			wildcard = q > 0
+			continue
+		}
+		accepted[name] = q > 0
+	}
+	for _, alg := range algorithms {
+		if ok, listed := accepted[alg]; ok || (!listed && wildcard) {
+			return alg
+		}
+	}
+	return ""
+}
+
+// compressWriter acumula o início da resposta até decidir se vale comprimir
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder io.WriteCloser
+	hijack  bool
+}
+
+// WriteHeader adia o envio do status até a decisão sobre a compressão
+func (cw *compressWriter) WriteHeader(status int) {
+	// Respostas informativas (1xx) não encerram o cabeçalho
+	if status >= 100 && status < 200 {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+	// Respostas sem corpo ou já codificadas seguem direto
+	if status == http.StatusNoContent || status == http.StatusNotModified || status == http.StatusPartialContent ||
+		cw.Header().Get("Content-Encoding") != "" {
+		cw.decide(false)
+	}
+}
+
+// Write guarda os dados até atingir o tamanho mínimo
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.encoder != nil {
+			return cw.encoder.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.decide(cw.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// compressible verifica Content-Type e Content-Length declarados
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	if length, err := strconv.Atoi(h.Get("Content-Length")); err == nil && length < cw.minSize {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(cw.buf)
+	}
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// decide envia o cabeçalho com ou sem compressão e descarrega o buffer
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	h := cw.Header()
+	if compress {
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		h.Set("Content-Encoding", cw.encoding)
+		// O ETag forte identifica os bytes originais
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.encoder = newEncoder(cw.encoding, cw.ResponseWriter)
+	}
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// newEncoder cria o compressor da codificação escolhida
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	switch encoding {
+	case EncodingBrotli:
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	case EncodingDeflate:
+		return zlib.NewWriter(w)
+	default:
+		return gzip.NewWriter(w)
+	}
+}
+
+// Flush decide a compressão com o que já foi escrito e repassa o Flush
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		_ = cw.decide(cw.status != 0 && len(cw.buf) > 0 && cw.compressible())
+	}
+	if f, ok := cw.encoder.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finaliza a resposta; corpos menores que o mínimo seguem sem compressão
+func (cw *compressWriter) Close() error {
+	if cw.hijack {
+		return nil
+	}
+	if !cw.decided {
+		if cw.status == 0 && len(cw.buf) == 0 {
+			return nil
+		}
+		return cw.decide(false)
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+	return nil
+}
+
+// Hijack repassa o Hijack quando suportado
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijack não suportado")
+	}
+	cw.hijack = true
+	return h.Hijack()
+}
+
+// Unwrap permite que http.ResponseController acesse o ResponseWriter original
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var compressCfg = config.CompressionConfig{Enabled: true, MinSize: 100, Algorithms: []string{"br", "gzip", "deflate"}}
+
+func bodyHandler(contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func compressRequest(t *testing.T, h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	mw, err := Compress(compressCfg)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	Chain(h, mw).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCompressEncodings(t *testing.T) {
+	body := strings.Repeat("bast serve comprime respostas grandes. ", 20)
+	h := bodyHandler("text/plain; charset=utf-8", body)
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"br":      func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+	}
+	for encoding, decode := range decoders {
+		rec := compressRequest(t, h, encoding)
+		assert.Equal(t, encoding, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `W/"abc"`, rec.Header().Get("ETag"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+		assert.Less(t, rec.Body.Len(), len(body))
+
+		r, err := decode(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(decoded), encoding)
+	}
+
+	// Preferência do servidor entre as aceitas; q=0 recusa
+	assert.Equal(t, "gzip", compressRequest(t, h, "deflate, gzip;q=0.5").Header().Get("Content-Encoding"))
+	assert.Equal(t, "deflate", compressRequest(t, h, "br;q=0, gzip;q=0, *").Header().Get("Content-Encoding"))
+	assert.Empty(t, compressRequest(t, h, "identity").Header().Get("Content-Encoding"))
+	assert.Empty(t, compressRequest(t, h, "").Header().Get("Content-Encoding"))
+}
+
+func TestCompressSkips(t *testing.T) {
+	small := compressRequest(t, bodyHandler("text/plain", "curto"), "gzip")
+	assert.Empty(t, small.Header().Get("Content-Encoding"))
+	assert.Equal(t, "curto", small.Body.String())
+
+	image := compressRequest(t, bodyHandler("image/png", strings.Repeat("x", 500)), "gzip")
+	assert.Empty(t, image.Header().Get("Content-Encoding"))
+	assert.Equal(t, 500, image.Body.Len())
+
+	noContent := compressRequest(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), "gzip")
+	assert.Equal(t, http.StatusNoContent, noContent.Code)
+	assert.Empty(t, noContent.Header().Get("Content-Encoding"))
+
+	_, err := Compress(config.CompressionConfig{Enabled: true, Algorithms: []string{"zstd"}})
+	assert.Error(t, err)
+	mw, err := Compress(config.CompressionConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, mw)
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+)
+
+// CORS responde preflights e adiciona os cabeçalhos Access-Control-* às
+// respostas de origens permitidas; retorna nil se desabilitado. Credenciais
+// exigem uma lista explícita de origens: com "*", qualquer site poderia fazer
+// leituras autenticadas
+func CORS(cfg config.CORSConfig) (Middleware, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Credentials && contains(cfg.Origins, "*") {
+		return nil, errors.New(`server.cors.credentials exige origens explícitas em server.cors.origins (ou --cors-origin) em vez de "*"`)
+	}
+	methods := strings.Join(cfg.Methods, ", ")
+	headers := strings.Join(cfg.Headers, ", ")
+	expose := strings.Join(cfg.ExposeHeaders, ", ")
+	anyHeader := contains(cfg.Headers, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed := originAllowed(cfg.Origins, origin)
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				if !allowed {
+					http.Error(w, "origem não permitida", http.StatusForbidden)
+					return
+				}
+				setAllowOrigin(h, cfg, origin)
+				h.Set("Access-Control-Allow-Methods", methods)
+				requested := r.Header.Get("Access-Control-Request-Headers")
+				if anyHeader && requested != "" {
+					h.Set("Access-Control-Allow-Headers", requested)
+				} else if headers != "" && !anyHeader {
+					h.Set("Access-Control-Allow-Headers", headers)
+				}
+				if cfg.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if allowed {
+				setAllowOrigin(h, cfg, origin)
+				if expose != "" {
+					h.Set("Access-Control-Expose-Headers", expose)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// setAllowOrigin define Allow-Origin; com credenciais a origem (já conferida
+// na lista) é ecoada, já que o navegador recusa "*" nesse caso
+func setAllowOrigin(h http.Header, cfg config.CORSConfig, origin string) {
+	if cfg.Credentials {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Credentials", "true")
+		return
+	}
+	if contains(cfg.Origins, "*") {
+		h.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+}
+
+// originAllowed compara a origem com a lista; "*" aceita qualquer origem e
+// "https://*.exemplo.com" aceita subdomínios
+func originAllowed(origins []string, origin string) bool {
+	for _, o := range origins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(o, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contains indica se a lista contém o valor
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
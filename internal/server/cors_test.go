@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	cfg := config.CORSConfig{
+		Enabled:       true,
+		Origins:       []string{"https://app.exemplo.com", "https://*.dev.exemplo.com"},
+		Methods:       []string{"GET", "POST"},
+		Headers:       []string{"*"},
+		ExposeHeaders: []string{"X-Request-ID"},
+		MaxAge:        600,
+	}
+	cors, err := CORS(cfg)
+	require.NoError(t, err)
+	h := Chain(teapot(), cors)
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/api", nil)
+	preflight.Header.Set("Origin", "https://a.dev.exemplo.com")
+	preflight.Header.Set("Access-Control-Request-Method", "POST")
+	preflight.Header.Set("Access-Control-Request-Headers", "Content-Type, Authorization")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, preflight)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://a.dev.exemplo.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+
+	preflight.Header.Set("Origin", "https://mal.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, preflight)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.Header.Set("Origin", "https://app.exemplo.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "https://app.exemplo.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID", rec.Header().Get("Access-Control-Expose-Headers"))
+
+	// Origem não permitida segue sem cabeçalhos CORS (o navegador bloqueia)
+	req.Header.Set("Origin", "https://mal.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSCredentials(t *testing.T) {
+	// "*" com credenciais permitiria leituras autenticadas de qualquer site
+	_, err := CORS(config.CORSConfig{Enabled: true, Origins: []string{"*"}, Credentials: true})
+	assert.Error(t, err)
+
+	cors, err := CORS(config.CORSConfig{Enabled: true, Origins: []string{"http://localhost:5173"}, Credentials: true})
+	require.NoError(t, err)
+	h := Chain(teapot(), cors)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "http://localhost:5173", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://malicioso.exemplo")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	disabled, err := CORS(config.CORSConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, disabled)
+}
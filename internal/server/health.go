@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+	"github.com/CristianSsousa/go-bast-cli/internal/portcheck"
+	"github.com/CristianSsousa/go-bast-cli/pkg/utils"
+)
+
+// Tipos de verificação de saúde suportados
+const (
+	HealthCheckTCP     = "tcp"
+	HealthCheckHTTP    = "http"
+	HealthCheckFile    = "file"
+	HealthCheckCommand = "command"
+)
+
+// Estados de uma verificação e do relatório de saúde
+const (
+	HealthOK       = "ok"
+	HealthFail     = "fail"
+	HealthDegraded = "degraded" // apenas verificações opcionais falharam
+)
+
+// HealthCheckResult resultado de uma verificação
+type HealthCheckResult struct {
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Status     string  `json:"status"`
+	Required   bool    `json:"required"`
+	DurationMS float64 `json:"duration_ms"`
+	Message    string  `json:"message,omitempty"`
+}
+
+// HealthReport resposta de /health/live e /health/ready
+type HealthReport struct {
+	Status     string              `json:"status"`
+	Checks     []HealthCheckResult `json:"checks"`
+	DurationMS float64             `json:"duration_ms"`
+}
+
+// Health executa as verificações declaradas em server.health.checks
+type Health struct {
+	checks []config.HealthCheckConfig
+	client *http.Client
+}
+
+// NewHealth valida as verificações e cria o executor
+func NewHealth(checks []config.HealthCheckConfig) (*Health, error) {
+	checks = append([]config.HealthCheckConfig(nil), checks...)
+	seen := make(map[string]bool)
+	for i := range checks {
+		c := &checks[i]
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("%s-%d", c.Type, i+1)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("verificação de saúde duplicada: %s", c.Name)
+		}
+		seen[c.Name] = true
+		if c.Timeout <= 0 {
+			c.Timeout = constants.DefaultNetworkTimeout * time.Second
+		}
+
+		switch c.Type {
+		case HealthCheckTCP, HealthCheckHTTP, HealthCheckFile:
+			if c.Target == "" {
+				return nil, fmt.Errorf("verificação %s: target é obrigatório para o tipo %s", c.Name, c.Type)
+			}
+		case HealthCheckCommand:
+			if len(c.Command) == 0 {
+				return nil, fmt.Errorf("verificação %s: command é obrigatório para o tipo %s", c.Name, c.Type)
+			}
+		default:
+			return nil, fmt.Errorf("verificação %s: tipo inválido '%s' (use tcp, http, file ou command)", c.Name, c.Type)
+		}
+	}
+	return &Health{
+		checks: checks,
+		client: &http.Client{
+			// Redirecionamentos contam como resposta do serviço verificado
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}, nil
+}
+
+// Live executa apenas as verificações marcadas com live
+func (h *Health) Live(ctx context.Context) HealthReport {
+	var checks []config.HealthCheckConfig
+	for _, c := range h.checks {
+		if c.Live {
+			checks = append(checks, c)
+		}
+	}
+	return h.run(ctx, checks)
+}
+
+// Ready executa todas as verificações
+func (h *Health) Ready(ctx context.Context) HealthReport {
+	return h.run(ctx, h.checks)
+}
+
+// run executa as verificações em paralelo, mantendo a ordem da configuração
+func (h *Health) run(ctx context.Context, checks []config.HealthCheckConfig) HealthReport {
+	start := time.Now()
+	results := make([]HealthCheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.check(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: HealthOK, Checks: results, DurationMS: durationMS(time.Since(start))}
+	for _, r := range results {
+		if r.Status == HealthOK {
+			continue
+		}
+		if r.Required {
+			report.Status = HealthFail
+			break
+		}
+		report.Status = HealthDegraded
+	}
+	return report
+}
+
+// check executa uma verificação respeitando seu timeout
+func (h *Health) check(ctx context.Context, c config.HealthCheckConfig) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	var message string
+	var err error
+	switch c.Type {
+	case HealthCheckTCP:
+		message, err = checkTCP(ctx, c)
+	case HealthCheckHTTP:
+		message, err = h.checkHTTP(ctx, c)
+	case HealthCheckFile:
+		message, err = checkFile(c)
+	case HealthCheckCommand:
+		message, err = checkCommand(ctx, c)
+	}
+
+	result := HealthCheckResult{
+		Name:       c.Name,
+		Type:       c.Type,
+		Status:     HealthOK,
+		Required:   !c.Optional,
+		DurationMS: durationMS(time.Since(start)),
+		Message:    message,
+	}
+	if err != nil {
+		result.Status = HealthFail
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// checkTCP verifica se a porta aceita conexões
+func checkTCP(ctx context.Context, c config.HealthCheckConfig) (string, error) {
+	result, err := portcheck.ProbeAddress(ctx, c.Target, c.Timeout)
+	if err != nil {
+		return "", err
+	}
+	if result.TimedOut {
+		return "", fmt.Errorf("timeout ao conectar em %s", result.Address)
+	}
+	if result.Err != nil {
+		return "", fmt.Errorf("%s inacessível: %v", result.Address, result.Err)
+	}
+	return fmt.Sprintf("%s aceitando conexões", result.Address), nil
+}
+
+// checkHTTP considera saudável uma resposta GET com status abaixo de 400
+func (h *Health) checkHTTP(ctx context.Context, c config.HealthCheckConfig) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Target, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("GET %s respondeu %s", c.Target, resp.Status)
+	}
+	return fmt.Sprintf("GET %s respondeu %s", c.Target, resp.Status), nil
+}
+
+// checkFile verifica se o arquivo existe
+func checkFile(c config.HealthCheckConfig) (string, error) {
+	if !utils.FileExists(c.Target) {
+		return "", fmt.Errorf("%s não encontrado", c.Target)
+	}
+	return fmt.Sprintf("%s encontrado", c.Target), nil
+}
+
+// checkCommand considera saudável o comando que termina com código 0
+func checkCommand(ctx context.Context, c config.HealthCheckConfig) (string, error) {
+	output, err := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...).CombinedOutput()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("comando excedeu o timeout de %v", c.Timeout)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("código de saída %d: %s", exitErr.ExitCode(), lastLine(output))
+		}
+		return "", err
+	}
+	return lastLine(output), nil
+}
+
+// lastLine retorna a última linha não vazia da saída do comando
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// durationMS converte a duração em milissegundos com precisão de microssegundos
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// LiveHandler retorna o handler de /health/live
+func (h *Health) LiveHandler() http.Handler {
+	return healthHandler(h.Live)
+}
+
+// ReadyHandler retorna o handler de /health/ready; responde 503 quando alguma
+// verificação obrigatória falha
+func (h *Health) ReadyHandler() http.Handler {
+	return healthHandler(h.Ready)
+}
+
+// healthHandler serializa o relatório de saúde
+func healthHandler(run func(context.Context) HealthReport) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := run(r.Context())
+		status := http.StatusOK
+		if report.Status == HealthFail {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, status, report)
+	})
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getHealth(t *testing.T, h http.Handler) (int, HealthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec.Code, report
+}
+
+func TestHealthReady(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	file := filepath.Join(t.TempDir(), "pronto")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+
+	health, err := NewHealth([]config.HealthCheckConfig{
+		{Name: "porta", Type: HealthCheckTCP, Target: ln.Addr().String(), Live: true},
+		{Name: "api", Type: HealthCheckHTTP, Target: up.URL},
+		{Name: "arquivo", Type: HealthCheckFile, Target: file},
+		{Name: "opcional", Type: HealthCheckFile, Target: file + ".x", Optional: true},
+	})
+	require.NoError(t, err)
+
+	code, report := getHealth(t, health.ReadyHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthDegraded, report.Status)
+	require.Len(t, report.Checks, 4)
+	assert.Equal(t, "porta", report.Checks[0].Name)
+	assert.Equal(t, HealthOK, report.Checks[1].Status)
+	assert.Equal(t, HealthFail, report.Checks[3].Status)
+	assert.False(t, report.Checks[3].Required)
+	assert.NotEmpty(t, report.Checks[3].Message)
+
+	code, report = getHealth(t, health.LiveHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthOK, report.Status)
+	assert.Len(t, report.Checks, 1)
+}
+
+func TestHealthRequiredFailure(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer up.Close()
+
+	health, err := NewHealth([]config.HealthCheckConfig{
+		{Name: "api", Type: HealthCheckHTTP, Target: up.URL},
+	})
+	require.NoError(t, err)
+
+	code, report := getHealth(t, health.ReadyHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthFail, report.Status)
+	assert.Contains(t, report.Checks[0].Message, "500")
+}
+
+func TestHealthCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("usa sh")
+	}
+	health, err := NewHealth([]config.HealthCheckConfig{
+		{Name: "ok", Type: HealthCheckCommand, Command: []string{"sh", "-c", "echo pronto"}},
+		{Name: "falha", Type: HealthCheckCommand, Command: []string{"sh", "-c", "echo quebrado; exit 3"}},
+		{Name: "lento", Type: HealthCheckCommand, Command: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	_, report := getHealth(t, health.ReadyHandler())
+	assert.Equal(t, HealthFail, report.Status)
+	assert.Equal(t, "pronto", report.Checks[0].Message)
+	assert.Equal(t, "código de saída 3: quebrado", report.Checks[1].Message)
+	assert.Contains(t, report.Checks[2].Message, "timeout")
+}
+
+func TestNewHealthErrors(t *testing.T) {
+	tests := [][]config.HealthCheckConfig{
+		{{Type: "ping", Target: "x"}},
+		{{Type: HealthCheckTCP}},
+		{{Type: HealthCheckCommand}},
+		{{Name: "a", Type: HealthCheckFile, Target: "x"}, {Name: "a", Type: HealthCheckFile, Target: "y"}},
+	}
+	for _, checks := range tests {
+		_, err := NewHealth(checks)
+		assert.Error(t, err)
+	}
+
+	// Sem verificações, a prontidão é sempre ok
+	health, err := NewHealth(nil)
+	require.NoError(t, err)
+	code, report := getHealth(t, health.ReadyHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthOK, report.Status)
+	assert.Empty(t, report.Checks)
+}
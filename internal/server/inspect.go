@@ -0,0 +1,366 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limites padrão do inspetor de requisições
+const (
+	DefaultInspectCapacity = 100
+	DefaultInspectMaxBody  = 1 << 20
+)
+
+// CapturedRequest requisição registrada pelo inspetor
+type CapturedRequest struct {
+	ID            int64               `json:"id"`
+	Time          time.Time           `json:"time"`
+	Method        string              `json:"method"`
+	Path          string              `json:"path"`
+	Query         map[string][]string `json:"query,omitempty"`
+	Headers       http.Header         `json:"headers"`
+	Host          string              `json:"host"`
+	RemoteAddr    string              `json:"remote_addr"`
+	Body          string              `json:"body,omitempty"`
+	BodySize      int64               `json:"body_size"`
+	BodyTruncated bool                `json:"body_truncated,omitempty"`
+	Duration      time.Duration       `json:"duration_ns"`
+}
+
+// ReplayResult resultado do reenvio de uma requisição capturada
+type ReplayResult struct {
+	Target   string        `json:"target"`
+	Status   int           `json:"status"`
+	Headers  http.Header   `json:"headers,omitempty"`
+	Body     string        `json:"body,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Inspector aceita qualquer requisição e guarda as mais recentes em um buffer circular
+type Inspector struct {
+	// OnCapture é chamado para cada requisição registrada
+	OnCapture func(CapturedRequest)
+
+	maxBody int64
+	client  *http.Client
+
+	mu     sync.RWMutex
+	ring   []CapturedRequest
+	next   int
+	count  int
+	lastID int64
+}
+
+// NewInspector cria um Inspector com a capacidade e o limite de corpo informados
+func NewInspector(capacity int, maxBody int64) *Inspector {
+	if capacity <= 0 {
+		capacity = DefaultInspectCapacity
+	}
+	if maxBody <= 0 {
+		maxBody = DefaultInspectMaxBody
+	}
+	return &Inspector{
+		maxBody: maxBody,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		ring:    make([]CapturedRequest, capacity),
+	}
+}
+
+// ServeHTTP registra a requisição e responde com o ID atribuído
+func (i *Inspector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var body bytes.Buffer
+	n, _ := io.Copy(&body, io.LimitReader(r.Body, i.maxBody))
+	// Descarta (e contabiliza) o restante do corpo acima do limite
+	extra, _ := io.Copy(io.Discard, r.Body)
+
+	captured := CapturedRequest{
+		Time:          start,
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Query:         r.URL.Query(),
+		Headers:       r.Header.Clone(),
+		Host:          r.Host,
+		RemoteAddr:    r.RemoteAddr,
+		Body:          body.String(),
+		BodySize:      n + extra,
+		BodyTruncated: extra > 0,
+		Duration:      time.Since(start),
+	}
+	captured = i.add(captured)
+
+	if i.OnCapture != nil {
+		i.OnCapture(captured)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]int64{"id": captured.ID})
+}
+
+// add insere a requisição no buffer, sobrescrevendo a mais antiga quando cheio
+func (i *Inspector) add(req CapturedRequest) CapturedRequest {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.lastID++
+	req.ID = i.lastID
+	i.ring[i.next] = req
+	i.next = (i.next + 1) % len(i.ring)
+	if i.count < len(i.ring) {
+		i.count++
+	}
+	return req
+}
+
+// Requests retorna as requisições guardadas em ordem cronológica com ID maior que since
+func (i *Inspector) Requests(since int64) []CapturedRequest {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	out := make([]CapturedRequest, 0, i.count)
+	start := (i.next - i.count + len(i.ring)) % len(i.ring)
+	for k := 0; k < i.count; k++ {
+		req := i.ring[(start+k)%len(i.ring)]
+		if req.ID > since {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// Get busca uma requisição guardada pelo ID
+func (i *Inspector) Get(id int64) (CapturedRequest, bool) {
+	for _, req := range i.Requests(id - 1) {
+		if req.ID == id {
+			return req, true
+		}
+	}
+	return CapturedRequest{}, false
+}
+
+// Clear remove todas as requisições guardadas
+func (i *Inspector) Clear() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.next = 0
+	i.count = 0
+}
+
+// Replay reenvia a requisição capturada para a URL base informada, mantendo
+// método, caminho, query, cabeçalhos e corpo
+func (i *Inspector) Replay(req CapturedRequest, target string) ReplayResult {
+	base, err := url.Parse(target)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return ReplayResult{Target: target, Error: fmt.Sprintf("URL de destino inválida: %s", target)}
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/") + req.Path
+	base.RawQuery = url.Values(req.Query).Encode()
+	result := ReplayResult{Target: base.String()}
+
+	out, err := http.NewRequest(req.Method, base.String(), strings.NewReader(req.Body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for k, v := range req.Headers {
+		if isHopByHopHeader(k) || strings.EqualFold(k, "Content-Length") {
+			continue
+		}
+		out.Header[k] = append([]string(nil), v...)
+	}
+
+	start := time.Now()
+	resp, err := i.client.Do(out)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, i.maxBody))
+	result.Status = resp.StatusCode
+	result.Headers = resp.Header
+	result.Body = string(body)
+	return result
+}
+
+// RegisterAdmin registra no mux a página do inspetor e a API /__requests
+func (i *Inspector) RegisterAdmin(mux *http.ServeMux) {
+	mux.HandleFunc("GET /__inspect", i.servePage)
+	mux.HandleFunc("GET "+inspectStylePath, serveAsset("text/css; charset=utf-8", inspectStyle))
+	mux.HandleFunc("GET "+inspectScriptPath, serveAsset("text/javascript; charset=utf-8", inspectScript))
+	mux.HandleFunc("GET /__requests", func(w http.ResponseWriter, r *http.Request) {
+		since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+		writeJSON(w, http.StatusOK, i.Requests(since))
+	})
+	mux.HandleFunc("DELETE /__requests", func(w http.ResponseWriter, r *http.Request) {
+		i.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /__requests/{id}", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := i.lookup(w, r)
+		if ok {
+			writeJSON(w, http.StatusOK, req)
+		}
+	})
+	mux.HandleFunc("POST /__requests/{id}/replay", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := i.lookup(w, r)
+		if !ok {
+			return
+		}
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "parâmetro target é obrigatório"})
+			return
+		}
+		result := i.Replay(req, target)
+		status := http.StatusOK
+		if result.Error != "" {
+			status = http.StatusBadGateway
+		}
+		writeJSON(w, status, result)
+	})
+}
+
+// lookup obtém a requisição indicada pelo parâmetro {id}, respondendo 404 se não existir
+func (i *Inspector) lookup(w http.ResponseWriter, r *http.Request) (CapturedRequest, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id inválido"})
+		return CapturedRequest{}, false
+	}
+	req, ok := i.Get(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "requisição não encontrada"})
+	}
+	return req, ok
+}
+
+func (i *Inspector) servePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.WriteString(w, inspectPage)
+}
+
+// serveAsset responde sempre com o mesmo conteúdo estático; usado pelos
+// estilos e scripts das páginas internas, que não podem ser embutidos no HTML
+// sob a Content-Security-Policy padrão
+func serveAsset(contentType, content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = io.WriteString(w, content)
+	}
+}
+
+// writeJSON responde com o valor serializado em JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// hopByHopHeaders cabeçalhos que não devem ser repassados entre conexões
+var hopByHopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// isHopByHopHeader verifica se o cabeçalho é específico da conexão
+func isHopByHopHeader(name string) bool {
+	for _, h := range hopByHopHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Caminhos dos recursos da página do inspetor, servidos na mesma origem para
+// funcionar com a Content-Security-Policy padrão (default-src 'self')
+const (
+	inspectStylePath  = "/__inspect.css"
+	inspectScriptPath = "/__inspect.js"
+)
+
+// inspectPage página HTML que acompanha /__requests e permite reenviar requisições
+const inspectPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>bast - inspetor de requisições</title>
+<link rel="stylesheet" href="` + inspectStylePath + `">
+</head>
+<body>
+<div id="list"><header>bast inspect <button id="clear">limpar</button></header><div id="items"></div></div>
+<div id="detail"><p>Envie requisições para qualquer caminho deste servidor.</p></div>
+<script src="` + inspectScriptPath + `"></script>
+</body>
+</html>
+`
+
+// inspectStyle folha de estilo da página do inspetor
+const inspectStyle = `body { font-family: sans-serif; margin: 0; display: flex; height: 100vh; }
+#list { width: 40%; overflow-y: auto; border-right: 1px solid #ccc; }
+#detail { flex: 1; overflow-y: auto; padding: 1em; }
+.item { padding: 0.5em 1em; border-bottom: 1px solid #eee; cursor: pointer; font-family: monospace; }
+.item:hover, .item.active { background: #eef; }
+.method { font-weight: bold; display: inline-block; width: 5em; }
+pre { background: #f6f6f6; padding: 0.5em; white-space: pre-wrap; word-break: break-all; }
+header { padding: 0.5em 1em; background: #333; color: #fff; }
+`
+
+// inspectScript script da página do inspetor
+const inspectScript = `let last = 0, requests = {};
+function esc(s) { const d = document.createElement('div'); d.textContent = s; return d.innerHTML; }
+async function poll() {
+  try {
+    const res = await fetch('/__requests?since=' + last);
+    const list = await res.json();
+    for (const r of list) {
+      requests[r.id] = r; last = r.id;
+      const el = document.createElement('div');
+      el.className = 'item'; el.id = 'req-' + r.id;
+      el.innerHTML = '<span class="method">' + esc(r.method) + '</span>' + esc(r.path) +
+        ' <small>' + new Date(r.time).toLocaleTimeString() + '</small>';
+      el.addEventListener('click', () => show(r.id));
+      document.getElementById('items').prepend(el);
+    }
+  } catch (e) {}
+  setTimeout(poll, 1000);
+}
+function show(id) {
+  const r = requests[id];
+  document.querySelectorAll('.item').forEach(e => e.classList.toggle('active', e.id === 'req-' + id));
+  document.getElementById('detail').innerHTML =
+    '<h2>#' + r.id + ' ' + esc(r.method) + ' ' + esc(r.path) + '</h2>' +
+    '<p>' + esc(r.remote_addr) + ' - ' + r.body_size + ' bytes' + (r.body_truncated ? ' (truncado)' : '') + '</p>' +
+    '<h3>Query</h3><pre>' + esc(JSON.stringify(r.query || {}, null, 2)) + '</pre>' +
+    '<h3>Cabeçalhos</h3><pre>' + esc(JSON.stringify(r.headers, null, 2)) + '</pre>' +
+    '<h3>Corpo</h3><pre>' + esc(r.body || '') + '</pre>' +
+    '<h3>Reenviar</h3><input id="target" size="40" placeholder="http://localhost:3000">' +
+    ' <button id="replay-button">reenviar</button><pre id="replay"></pre>';
+  document.getElementById('replay-button').addEventListener('click', () => replay(id));
+}
+async function replay(id) {
+  const target = document.getElementById('target').value;
+  const res = await fetch('/__requests/' + id + '/replay?target=' + encodeURIComponent(target), {method: 'POST'});
+  document.getElementById('replay').textContent = JSON.stringify(await res.json(), null, 2);
+}
+async function clearAll() {
+  await fetch('/__requests', {method: 'DELETE'});
+  document.getElementById('items').innerHTML = ''; requests = {};
+}
+document.getElementById('clear').addEventListener('click', clearAll);
+poll();
+`
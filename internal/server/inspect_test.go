@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectorRingBuffer(t *testing.T) {
+	insp := NewInspector(3, 4)
+
+	for _, p := range []string{"/a", "/b", "/c", "/d"} {
+		rec := httptest.NewRecorder()
+		insp.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, p+"?x=1", strings.NewReader("123456")))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	reqs := insp.Requests(0)
+	require.Len(t, reqs, 3)
+	assert.Equal(t, "/b", reqs[0].Path)
+	assert.Equal(t, "/d", reqs[2].Path)
+	assert.Equal(t, int64(4), reqs[2].ID)
+	assert.Equal(t, "1234", reqs[2].Body)
+	assert.Equal(t, int64(6), reqs[2].BodySize)
+	assert.True(t, reqs[2].BodyTruncated)
+	assert.Equal(t, []string{"1"}, reqs[2].Query["x"])
+
+	assert.Len(t, insp.Requests(3), 1)
+
+	_, ok := insp.Get(1)
+	assert.False(t, ok)
+
+	insp.Clear()
+	assert.Empty(t, insp.Requests(0))
+}
+
+func TestInspectorAdminAndReplay(t *testing.T) {
+	var replayed *http.Request
+	var replayedBody string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		replayed = r
+		body, _ := io.ReadAll(r.Body)
+		replayedBody = string(body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("recebido"))
+	}))
+	defer upstream.Close()
+
+	insp := NewInspector(10, 0)
+	mux := http.NewServeMux()
+	mux.Handle("/", insp)
+	insp.RegisterAdmin(mux)
+
+	req := httptest.NewRequest(http.MethodPut, "/webhook?event=push", strings.NewReader(`{"ok":true}`))
+	req.Header.Set("X-Signature", "abc")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__requests/1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var captured CapturedRequest
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &captured))
+	assert.Equal(t, http.MethodPut, captured.Method)
+	assert.Equal(t, "abc", captured.Headers.Get("X-Signature"))
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/__requests/1/replay?target="+upstream.URL+"/base", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result ReplayResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusAccepted, result.Status)
+	assert.Equal(t, "recebido", result.Body)
+
+	require.NotNil(t, replayed)
+	assert.Equal(t, http.MethodPut, replayed.Method)
+	assert.Equal(t, "/base/webhook", replayed.URL.Path)
+	assert.Equal(t, "push", replayed.URL.Query().Get("event"))
+	assert.Equal(t, "abc", replayed.Header.Get("X-Signature"))
+	assert.Equal(t, `{"ok":true}`, replayedBody)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__requests/99", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestInspectPageWithSecurityHeaders(t *testing.T) {
+	insp := NewInspector(10, 0)
+	mux := http.NewServeMux()
+	mux.Handle("/", insp)
+	insp.RegisterAdmin(mux)
+	h := Chain(mux, SecurityHeaders(config.SecurityHeadersConfig{Enabled: true, CSP: "default-src 'self'"}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__inspect", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "default-src 'self'", rec.Header().Get("Content-Security-Policy"))
+	page := rec.Body.String()
+	assertNoInlineCode(t, page)
+	assert.Contains(t, page, `<link rel="stylesheet" href="/__inspect.css">`)
+	assert.Contains(t, page, `<script src="/__inspect.js"></script>`)
+
+	for path, contentType := range map[string]string{
+		"/__inspect.css": "text/css; charset=utf-8",
+		"/__inspect.js":  "text/javascript; charset=utf-8",
+	} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, contentType, rec.Header().Get("Content-Type"), path)
+		assert.NotEmpty(t, rec.Body.String(), path)
+	}
+	// Os recursos da página não são capturados como requisições
+	assert.Empty(t, insp.Requests(0))
+}
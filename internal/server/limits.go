@@ -0,0 +1,443 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/CristianSsousa/go-bast-cli/pkg/utils"
+)
+
+// clientIdleSweep intervalo entre remoções de clientes inativos do limitador por IP
+const clientIdleSweep = time.Minute
+
+// tokenBucket balde de fichas reabastecido continuamente
+type tokenBucket struct {
+	rate   float64 // fichas por segundo
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// refill atualiza as fichas disponíveis até o instante informado
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// take consome uma ficha; sem fichas, retorna quanto tempo falta para a próxima
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := (1 - b.tokens) / b.rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// RateLimitState estado do limitador exposto em /__limits
+type RateLimitState struct {
+	PerIP          *BucketState `json:"per_ip,omitempty"`
+	Global         *BucketState `json:"global,omitempty"`
+	Clients        int          `json:"clients"`
+	RejectedPerIP  uint64       `json:"rejected_per_ip"`
+	RejectedGlobal uint64       `json:"rejected_global"`
+}
+
+// BucketState configuração e fichas disponíveis de um balde
+type BucketState struct {
+	Rate   float64  `json:"rate"`
+	Burst  int      `json:"burst"`
+	Tokens *float64 `json:"tokens,omitempty"` // apenas no balde global
+}
+
+// RateLimiter limita requisições por IP e no total com token bucket
+type RateLimiter struct {
+	rate   float64
+	burst  int
+	exempt []string
+	now    func() time.Time
+
+	mu        sync.Mutex
+	global    *tokenBucket
+	clients   map[netip.Addr]*tokenBucket
+	lastSweep time.Time
+
+	rejectedIP     atomic.Uint64
+	rejectedGlobal atomic.Uint64
+}
+
+// NewRateLimiter cria o limitador; retorna nil se nenhuma taxa estiver configurada
+func NewRateLimiter(cfg config.LimitsConfig) (*RateLimiter, error) {
+	if cfg.Rate < 0 || cfg.GlobalRate < 0 || cfg.Burst < 0 || cfg.GlobalBurst < 0 {
+		return nil, errors.New("limites de taxa não podem ser negativos")
+	}
+	if cfg.Rate == 0 && cfg.GlobalRate == 0 {
+		return nil, nil
+	}
+	l := &RateLimiter{
+		rate:    cfg.Rate,
+		burst:   cfg.Burst,
+		exempt:  cfg.Exempt,
+		now:     time.Now,
+		clients: make(map[netip.Addr]*tokenBucket),
+	}
+	l.lastSweep = l.now()
+	if cfg.GlobalRate > 0 {
+		l.global = newTokenBucket(cfg.GlobalRate, cfg.GlobalBurst, l.now())
+	}
+	return l, nil
+}
+
+// Allow consome uma ficha do IP e do balde global; se recusar, informa o
+// tempo de espera sugerido
+func (l *RateLimiter) Allow(addr netip.Addr) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	var client *tokenBucket
+	if l.rate > 0 {
+		l.sweep(now)
+		client = l.clients[addr]
+		if client == nil {
+			client = newTokenBucket(l.rate, l.burst, now)
+			l.clients[addr] = client
+		}
+		client.refill(now)
+		if client.tokens < 1 {
+			_, wait := client.take(now)
+			l.rejectedIP.Add(1)
+			return false, wait
+		}
+	}
+	if l.global != nil {
+		if ok, wait := l.global.take(now); !ok {
+			l.rejectedGlobal.Add(1)
+			return false, wait
+		}
+	}
+	if client != nil {
+		client.tokens--
+	}
+	return true, 0
+}
+
+// sweep remove clientes cujo balde já estaria cheio; requer l.mu
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < clientIdleSweep {
+		return
+	}
+	l.lastSweep = now
+	for addr, b := range l.clients {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(l.clients, addr)
+		}
+	}
+}
+
+// Middleware responde 429 com Retry-After quando o limite é excedido
+func (l *RateLimiter) Middleware() Middleware {
+	if l == nil {
+		return nil
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if matchPath(l.exempt, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			addr, _ := remoteAddr(r)
+			if ok, wait := l.Allow(addr); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// State retorna a configuração e os contadores atuais
+func (l *RateLimiter) State() RateLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := RateLimitState{
+		Clients:        len(l.clients),
+		RejectedPerIP:  l.rejectedIP.Load(),
+		RejectedGlobal: l.rejectedGlobal.Load(),
+	}
+	if l.rate > 0 {
+		burst := l.burst
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(l.rate)))
+		}
+		state.PerIP = &BucketState{Rate: l.rate, Burst: burst}
+	}
+	if l.global != nil {
+		l.global.refill(l.now())
+		tokens := math.Floor(l.global.tokens*100) / 100
+		state.Global = &BucketState{Rate: l.global.rate, Burst: int(l.global.burst), Tokens: &tokens}
+	}
+	return state
+}
+
+// MaxBodySize limita o corpo das requisições; corpos declarados acima do limite
+// recebem 413 imediatamente e os demais são interrompidos ao exceder o limite.
+// Retorna nil se o limite for vazio
+func MaxBodySize(size string) (Middleware, int64, error) {
+	if size == "" {
+		return nil, 0, nil
+	}
+	limit, err := utils.ParseSize(size)
+	if err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		return nil, 0, nil
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				http.Error(w, fmt.Sprintf("corpo excede o limite de %s", formatSize(limit)), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}, limit, nil
+}
+
+// connRejectTimeout prazo para enviar a recusa a uma conexão acima do limite
+const connRejectTimeout = time.Second
+
+// connRejectBody corpo da resposta enviada às conexões acima do limite
+const connRejectBody = "limite de conexões simultâneas atingido\n"
+
+// connRejectResponse resposta enviada, em HTTP sem TLS, às conexões acima do limite
+var connRejectResponse = "HTTP/1.1 503 Service Unavailable\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Retry-After: 1\r\n" +
+	"Connection: close\r\n" +
+	"Content-Length: " + strconv.Itoa(len(connRejectBody)) + "\r\n" +
+	"\r\n" + connRejectBody
+
+// ConnLimiter limita as conexões simultâneas em uso; conexões acima do limite
+// são recusadas assim que aceitas. Conexões keep-alive ociosas liberam a vaga
+// até a próxima requisição, para não bloquear novos clientes
+type ConnLimiter struct {
+	max      int
+	mu       sync.Mutex
+	active   int
+	rejected atomic.Int64
+}
+
+// NewConnLimiter cria o limitador; retorna nil se max não for positivo
+func NewConnLimiter(max int) *ConnLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &ConnLimiter{max: max}
+}
+
+// Max retorna o limite de conexões
+func (c *ConnLimiter) Max() int {
+	return c.max
+}
+
+// Active retorna as conexões ocupando vaga no momento (as ociosas não contam)
+func (c *ConnLimiter) Active() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(c.active)
+}
+
+// Rejected retorna o total de conexões recusadas por falta de vaga
+func (c *ConnLimiter) Rejected() int64 {
+	return c.rejected.Load()
+}
+
+// Listener envolve o listener aplicando o limite; com respond, as conexões
+// recusadas recebem uma resposta 503 antes de serem fechadas (use false quando
+// o listener for envolvido por TLS depois, pois a resposta não seria legível)
+func (c *ConnLimiter) Listener(ln net.Listener, respond bool) net.Listener {
+	return &limitListener{Listener: ln, limiter: c, respond: respond}
+}
+
+// ConnState deve ser encadeado em http.Server.ConnState: libera a vaga das
+// conexões ociosas e a ocupa de novo quando chega outra requisição
+func (c *ConnLimiter) ConnState(conn net.Conn, state http.ConnState) {
+	if tc, ok := conn.(*tls.Conn); ok {
+		conn = tc.NetConn()
+	}
+	lc, ok := conn.(*limitConn)
+	if !ok || lc.limiter != c {
+		return
+	}
+	switch state {
+	case http.StateIdle:
+		lc.setHeld(false)
+	case http.StateActive:
+		// A requisição já chegou: a vaga é ocupada mesmo acima do limite, que
+		// volta a valer para as próximas conexões
+		lc.setHeld(true)
+	}
+}
+
+// acquire ocupa uma vaga, se houver
+func (c *ConnLimiter) acquire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active >= c.max {
+		return false
+	}
+	c.active++
+	return true
+}
+
+// limitListener listener que recusa conexões sem vaga disponível
+type limitListener struct {
+	net.Listener
+	limiter *ConnLimiter
+	respond bool
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.limiter.acquire() {
+			return &limitConn{Conn: conn, limiter: l.limiter, held: true}, nil
+		}
+		l.limiter.rejected.Add(1)
+		go reject(conn, l.respond)
+	}
+}
+
+// reject recusa a conexão, respondendo 503 se respond for verdadeiro; o corpo
+// da requisição é descartado antes de fechar, para que o cliente receba a
+// resposta em vez de um reset da conexão
+func reject(conn net.Conn, respond bool) {
+	defer conn.Close()
+	if !respond {
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(connRejectTimeout))
+	if _, err := io.WriteString(conn, connRejectResponse); err != nil {
+		return
+	}
+	if tc, ok := conn.(*net.TCPConn); ok {
+		_ = tc.CloseWrite()
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(conn, 64<<10))
+}
+
+// limitConn ocupa uma vaga enquanto está em uso e a libera ao ser fechada
+type limitConn struct {
+	net.Conn
+	limiter *ConnLimiter
+	held    bool // protegido por limiter.mu
+	closed  bool // protegido por limiter.mu
+}
+
+// setHeld ocupa ou libera a vaga da conexão
+func (c *limitConn) setHeld(held bool) {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	if c.closed || c.held == held {
+		return
+	}
+	c.held = held
+	if held {
+		c.limiter.active++
+	} else {
+		c.limiter.active--
+	}
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		if c.held {
+			c.held = false
+			c.limiter.active--
+		}
+	}
+	return err
+}
+
+// LimitsCollector expõe o estado dos limitadores em /metrics; aceita limitadores nil
+func LimitsCollector(rl *RateLimiter, conns *ConnLimiter) MetricsCollector {
+	return func(w *MetricsWriter) {
+		if rl != nil {
+			state := rl.State()
+			name := metricsNamespace + "_ratelimit_rejected_total"
+			w.Header(name, "counter", "Requisições recusadas pelo limite de taxa.")
+			w.Sample(name, float64(state.RejectedPerIP), "scope", "ip")
+			w.Sample(name, float64(state.RejectedGlobal), "scope", "global")
+			name = metricsNamespace + "_ratelimit_clients"
+			w.Header(name, "gauge", "Clientes acompanhados pelo limite por IP.")
+			w.Sample(name, float64(state.Clients))
+			if state.Global != nil {
+				name = metricsNamespace + "_ratelimit_global_tokens"
+				w.Header(name, "gauge", "Fichas disponíveis no limite global.")
+				w.Sample(name, *state.Global.Tokens)
+			}
+		}
+		if conns != nil {
+			name := metricsNamespace + "_connections_active"
+			w.Header(name, "gauge", "Conexões em uso (as ociosas não contam).")
+			w.Sample(name, float64(conns.Active()))
+			name = metricsNamespace + "_connections_rejected_total"
+			w.Header(name, "counter", "Conexões recusadas pelo limite de conexões.")
+			w.Sample(name, float64(conns.Rejected()))
+			name = metricsNamespace + "_connections_max"
+			w.Header(name, "gauge", "Limite de conexões simultâneas.")
+			w.Sample(name, float64(conns.Max()))
+		}
+	}
+}
+
+// LimitsHandler expõe o estado dos limitadores em JSON; aceita limitadores nil
+func LimitsHandler(rl *RateLimiter, conns *ConnLimiter, maxBody int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out := map[string]interface{}{}
+		if rl != nil {
+			out["rate_limit"] = rl.State()
+		}
+		if conns != nil {
+			out["connections"] = map[string]int64{"active": conns.Active(), "max": int64(conns.Max()), "rejected": conns.Rejected()}
+		}
+		if maxBody > 0 {
+			out["max_body_size"] = maxBody
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+}
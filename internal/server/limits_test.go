@@ -0,0 +1,276 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock relógio controlado pelos testes
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestRateLimiter(t *testing.T, cfg config.LimitsConfig) (*RateLimiter, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l, err := NewRateLimiter(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, l)
+	l.now = clock.now
+	l.lastSweep = clock.t
+	if l.global != nil {
+		l.global.last = clock.t
+	}
+	return l, clock
+}
+
+func TestRateLimiterPerIP(t *testing.T) {
+	l, clock := newTestRateLimiter(t, config.LimitsConfig{Rate: 2, Burst: 3})
+	a := netip.MustParseAddr("10.0.0.1")
+	b := netip.MustParseAddr("10.0.0.2")
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow(a)
+		assert.True(t, ok, "requisição %d dentro do burst", i)
+	}
+	ok, wait := l.Allow(a)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Outro IP tem balde próprio
+	ok, _ = l.Allow(b)
+	assert.True(t, ok)
+
+	clock.advance(500 * time.Millisecond)
+	ok, _ = l.Allow(a)
+	assert.True(t, ok)
+
+	state := l.State()
+	assert.Equal(t, 2, state.Clients)
+	assert.Equal(t, uint64(1), state.RejectedPerIP)
+	assert.Equal(t, &BucketState{Rate: 2, Burst: 3}, state.PerIP)
+	assert.Nil(t, state.Global)
+
+	// Clientes ociosos são removidos após o intervalo de limpeza
+	clock.advance(2 * clientIdleSweep)
+	_, _ = l.Allow(b)
+	assert.Equal(t, 1, l.State().Clients)
+}
+
+func TestRateLimiterGlobal(t *testing.T) {
+	l, clock := newTestRateLimiter(t, config.LimitsConfig{Rate: 10, GlobalRate: 1, GlobalBurst: 2})
+
+	for i := 1; i <= 2; i++ {
+		ok, _ := l.Allow(netip.AddrFrom4([4]byte{10, 0, 0, byte(i)}))
+		assert.True(t, ok)
+	}
+	ok, wait := l.Allow(netip.MustParseAddr("10.0.0.3"))
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+
+	state := l.State()
+	assert.Equal(t, uint64(1), state.RejectedGlobal)
+	assert.Equal(t, uint64(0), state.RejectedPerIP)
+	require.NotNil(t, state.Global)
+	assert.Equal(t, 0.0, *state.Global.Tokens)
+
+	clock.advance(time.Second)
+	ok, _ = l.Allow(netip.MustParseAddr("10.0.0.3"))
+	assert.True(t, ok)
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	l, err := NewRateLimiter(config.LimitsConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, l)
+	assert.Nil(t, l.Middleware())
+
+	_, err = NewRateLimiter(config.LimitsConfig{Rate: -1})
+	assert.Error(t, err)
+
+	// Sem burst, o balde comporta uma segunda de requisições
+	l, err = NewRateLimiter(config.LimitsConfig{Rate: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, 1, l.State().PerIP.Burst)
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	l, _ := newTestRateLimiter(t, config.LimitsConfig{Rate: 0.5, Burst: 1, Exempt: []string{"/health"}})
+	h := Chain(teapot(), l.Middleware())
+
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/", "192.0.2.1:1234", nil).Code)
+	rec := authRequest(h, "/", "192.0.2.1:1234", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusTeapot, authRequest(h, "/health", "192.0.2.1:1234", nil).Code)
+}
+
+func TestMaxBodySize(t *testing.T) {
+	mw, limit, err := MaxBodySize("10B")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), limit)
+
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}), mw)
+
+	do := func(body io.Reader, length int64) int {
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.ContentLength = length
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do(strings.NewReader("pequeno"), 7))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do(strings.NewReader(strings.Repeat("x", 20)), 20))
+	// Corpo sem tamanho declarado é interrompido ao exceder o limite
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do(io.MultiReader(strings.NewReader(strings.Repeat("x", 20))), -1))
+
+	mw, _, err = MaxBodySize("")
+	assert.NoError(t, err)
+	assert.Nil(t, mw)
+	_, _, err = MaxBodySize("dez")
+	assert.Error(t, err)
+}
+
+func TestConnLimiter(t *testing.T) {
+	assert.Nil(t, NewConnLimiter(0))
+
+	limiter := NewConnLimiter(1)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := New(&http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})})
+	srv.ConnLimiter = limiter
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _, _ = srv.Serve(ctx, ln, time.Second) }()
+	url := "http://" + ln.Addr().String() + "/"
+
+	// Uma conexão aberta, ainda sem requisição, ocupa a única vaga
+	first, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer first.Close()
+	require.Eventually(t, func() bool { return limiter.Active() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	// A conexão seguinte é recusada na hora, sem esperar na fila do sistema
+	other := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 2 * time.Second}
+	resp, err := other.Get(url)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+	assert.Contains(t, string(body), "limite de conexões")
+	assert.Equal(t, int64(1), limiter.Rejected())
+
+	// Depois da resposta, a conexão keep-alive fica ociosa e libera a vaga
+	_, err = io.WriteString(first, "GET / HTTP/1.1\r\nHost: bast\r\n\r\n")
+	require.NoError(t, err)
+	buf := make([]byte, 512)
+	n, err := first.Read(buf)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), "200 OK")
+	require.Eventually(t, func() bool { return limiter.Active() == 0 }, 2*time.Second, 10*time.Millisecond)
+
+	resp, err = other.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Eventually(t, func() bool { return limiter.Active() == 0 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestConnLimiterWithoutResponse(t *testing.T) {
+	limiter := NewConnLimiter(1)
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln := limiter.Listener(inner, false)
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	first, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	defer first.Close()
+	server1 := <-accepted
+
+	// Sem vaga, a conexão é apenas fechada (listener envolvido por TLS)
+	second, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	defer second.Close()
+	_ = second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := second.Read(make([]byte, 1))
+	assert.Zero(t, n)
+	assert.ErrorIs(t, err, io.EOF)
+
+	require.NoError(t, server1.Close())
+	assert.Equal(t, int64(0), limiter.Active())
+	third, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	defer third.Close()
+	select {
+	case server3 := <-accepted:
+		server3.Close()
+	case <-time.After(2 * time.Second):
+		t.Fatal("conexão não foi aceita após liberar a vaga")
+	}
+}
+
+func TestLimitsHandlerAndCollector(t *testing.T) {
+	l, _ := newTestRateLimiter(t, config.LimitsConfig{Rate: 1, GlobalRate: 5})
+	_, _ = l.Allow(netip.MustParseAddr("10.0.0.1"))
+	_, _ = l.Allow(netip.MustParseAddr("10.0.0.1"))
+	conns := NewConnLimiter(8)
+
+	rec := httptest.NewRecorder()
+	LimitsHandler(l, conns, 1024).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__limits", nil))
+	var out struct {
+		RateLimit   RateLimitState   `json:"rate_limit"`
+		Connections map[string]int64 `json:"connections"`
+		MaxBodySize int64            `json:"max_body_size"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	assert.Equal(t, uint64(1), out.RateLimit.RejectedPerIP)
+	assert.Equal(t, int64(8), out.Connections["max"])
+	assert.Contains(t, out.Connections, "rejected")
+	assert.Equal(t, int64(1024), out.MaxBodySize)
+
+	m := NewMetrics()
+	m.AddCollector(LimitsCollector(l, conns))
+	var buf bytes.Buffer
+	m.Write(&buf)
+	text := buf.String()
+	assert.Contains(t, text, `bast_ratelimit_rejected_total{scope="ip"} 1`)
+	assert.Contains(t, text, "bast_ratelimit_clients 1")
+	assert.Contains(t, text, "bast_connections_max 8")
+	assert.Contains(t, text, "bast_connections_rejected_total 0")
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Caminhos usados pelo live reload
+const (
+	LiveReloadEventsPath = "/__livereload"
+	LiveReloadScriptPath = "/__livereload.js"
+)
+
+// liveReloadDebounce espera após a última alteração antes de notificar, para
+// agrupar gravações em sequência (editores, builds)
+const liveReloadDebounce = 100 * time.Millisecond
+
+// DefaultWatchIgnore padrões ignorados pelo live reload por padrão
+var DefaultWatchIgnore = []string{".git", "node_modules", "*.swp", "*~"}
+
+// liveReloadScript recarrega a página a cada evento "reload" e troca apenas as
+// folhas de estilo alteradas nos eventos "css"
+const liveReloadScript = `(function () {
+  if (!window.EventSource) return;
+  var es = new EventSource("` + LiveReloadEventsPath + `");
+  es.addEventListener("reload", function () { location.reload(); });
+  es.addEventListener("css", function (e) {
+    var files = JSON.parse(e.data), swapped = 0;
+    document.querySelectorAll('link[rel="stylesheet"]').forEach(function (link) {
+      var url = new URL(link.href, location.href);
+      if (url.origin !== location.origin) return;
+      if (!files.some(function (f) { return url.pathname.endsWith(f); })) return;
+      url.searchParams.set("livereload", Date.now());
+      link.href = url.toString();
+      swapped++;
+    });
+    if (!swapped) location.reload();
+  });
+})();
+`
+
+// liveReloadTag elemento inserido nas páginas HTML
+const liveReloadTag = `<script src="` + LiveReloadScriptPath + `"></script>`
+
+// LiveReload observa um diretório e avisa as páginas abertas, por SSE, para
+// recarregar quando algum arquivo muda
+type LiveReload struct {
+	// OnChange é chamado, se definido, com os arquivos alterados (relativos à
+	// raiz) e o evento enviado: "reload" ou "css"
+	OnChange func(files []string, event string)
+
+	root      string
+	ignore    []string
+	cssReload bool
+	broker    *SSEBroker
+}
+
+// NewLiveReload cria o live reload para root; com cssHotSwap, alterações apenas em
+// arquivos .css trocam as folhas de estilo sem recarregar a página
+func NewLiveReload(root string, ignore []string, cssHotSwap bool) (*LiveReload, error) {
+	for _, pattern := range ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("padrão de exclusão inválido '%s': %w", pattern, err)
+		}
+	}
+	return &LiveReload{root: root, ignore: ignore, cssReload: cssHotSwap, broker: NewSSEBroker()}, nil
+}
+
+// Register expõe o stream de eventos e o script no mux
+func (l *LiveReload) Register(mux *http.ServeMux) {
+	mux.Handle("GET "+LiveReloadEventsPath, l.broker)
+	mux.HandleFunc("GET "+LiveReloadScriptPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write([]byte(liveReloadScript))
+	})
+}
+
+// Clients retorna o número de páginas conectadas
+func (l *LiveReload) Clients() int {
+	return l.broker.Clients()
+}
+
+// ignored verifica se algum segmento do caminho relativo coincide com os padrões
+func (l *LiveReload) ignored(rel string) bool {
+	for _, segment := range strings.Split(filepath.ToSlash(rel), "/") {
+		for _, pattern := range l.ignore {
+			if ok, _ := filepath.Match(pattern, segment); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addTree observa dir e seus subdiretórios que não estejam excluídos
+func (l *LiveReload) addTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Diretórios removidos durante a varredura são ignorados
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel, _ := filepath.Rel(l.root, path); rel != "." && l.ignored(rel) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// Watch observa a árvore de diretórios recursivamente até que done seja fechado;
+// ao fechar, encerra também as conexões das páginas
+func (l *LiveReload) Watch(done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("erro ao criar observador de arquivos: %w", err)
+	}
+	if err := l.addTree(watcher, l.root); err != nil {
+		watcher.Close()
+		return fmt.Errorf("erro ao observar %s: %w", l.root, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		defer l.broker.Close()
+		changed := make(map[string]bool)
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				rel, err := filepath.Rel(l.root, event.Name)
+				if err != nil || event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) || l.ignored(rel) {
+					continue
+				}
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						_ = l.addTree(watcher, event.Name)
+					}
+				}
+				changed[filepath.ToSlash(rel)] = true
+				debounce = time.After(liveReloadDebounce)
+			case <-debounce:
+				debounce = nil
+				files := make([]string, 0, len(changed))
+				for f := range changed {
+					files = append(files, f)
+				}
+				clear(changed)
+				sort.Strings(files)
+				l.Notify(files)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Notify avisa as páginas sobre os arquivos alterados: "css" com a lista de
+// caminhos se todos forem folhas de estilo (e o hot swap estiver ativo), ou
+// "reload" caso contrário
+func (l *LiveReload) Notify(files []string) {
+	event := "reload"
+	data := "{}"
+	if l.cssReload && len(files) > 0 && allCSS(files) {
+		paths := make([]string, len(files))
+		for i, f := range files {
+			paths[i] = "/" + f
+		}
+		encoded, _ := json.Marshal(paths)
+		event, data = "css", string(encoded)
+	}
+	l.broker.Publish(SSEEvent{Event: event, Data: data})
+	if l.OnChange != nil {
+		l.OnChange(files, event)
+	}
+}
+
+// allCSS verifica se todos os arquivos são folhas de estilo
+func allCSS(files []string) bool {
+	for _, f := range files {
+		if !strings.EqualFold(filepath.Ext(f), ".css") {
+			return false
+		}
+	}
+	return true
+}
+
+// Inject insere o script de live reload antes de </body> nas respostas HTML
+// completas (200) do handler. HEAD é atendido como GET, para que o
+// Content-Length seja o da página com o script, mas sem corpo
+func (l *LiveReload) Inject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		iw := &injectWriter{ResponseWriter: w, head: r.Method == http.MethodHead}
+		if iw.head {
+			r = r.Clone(r.Context())
+			r.Method = http.MethodGet
+		}
+		next.ServeHTTP(iw, r)
+		iw.finish()
+	})
+}
+
+// injectWriter acumula respostas HTML para inserir o script ao final
+type injectWriter struct {
+	http.ResponseWriter
+	head   bool // requisição HEAD atendida como GET: o corpo é descartado
+	status int
+	inject bool
+	buf    bytes.Buffer
+}
+
+func (iw *injectWriter) WriteHeader(status int) {
+	if iw.status != 0 {
+		return
+	}
+	iw.status = status
+	h := iw.Header()
+	iw.inject = status == http.StatusOK && h.Get("Content-Encoding") == "" &&
+		strings.HasPrefix(strings.ToLower(h.Get("Content-Type")), "text/html")
+	if !iw.inject {
+		iw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (iw *injectWriter) Write(b []byte) (int, error) {
+	if iw.status == 0 {
+		iw.WriteHeader(http.StatusOK)
+	}
+	if iw.inject {
+		return iw.buf.Write(b)
+	}
+	if iw.head {
+		// Interrompe a cópia de respostas que não serão alteradas
+		return 0, http.ErrBodyNotAllowed
+	}
+	return iw.ResponseWriter.Write(b)
+}
+
+// finish envia a página acumulada com o script inserido
+func (iw *injectWriter) finish() {
+	if !iw.inject {
+		return
+	}
+	body := iw.buf.Bytes()
+	tag := []byte(liveReloadTag)
+	if i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); i >= 0 {
+		body = append(body[:i:i], append(tag, body[i:]...)...)
+	} else {
+		body = append(body, tag...)
+	}
+	h := iw.Header()
+	h.Del("Accept-Ranges")
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	iw.ResponseWriter.WriteHeader(iw.status)
+	if !iw.head {
+		_, _ = iw.ResponseWriter.Write(body)
+	}
+}
+
+// Unwrap permite que http.ResponseController acesse o ResponseWriter original
+func (iw *injectWriter) Unwrap() http.ResponseWriter {
+	return iw.ResponseWriter
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveReloadIgnored(t *testing.T) {
+	l, err := NewLiveReload(t.TempDir(), DefaultWatchIgnore, true)
+	require.NoError(t, err)
+	assert.True(t, l.ignored(".git"))
+	assert.True(t, l.ignored("web/node_modules/lib/index.js"))
+	assert.True(t, l.ignored("index.html.swp"))
+	assert.False(t, l.ignored("css/site.css"))
+
+	_, err = NewLiveReload(t.TempDir(), []string{"[invalido"}, true)
+	assert.Error(t, err)
+}
+
+func TestLiveReloadInject(t *testing.T) {
+	root := newStaticFixture(t)
+	static, err := NewStaticHandler(root, StaticOptions{})
+	require.NoError(t, err)
+	l, err := NewLiveReload(root, nil, true)
+	require.NoError(t, err)
+	h := l.Inject(static)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), liveReloadTag)
+	assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get("Content-Length"))
+
+	// HEAD informa o mesmo Content-Length do GET, sem corpo
+	head := httptest.NewRecorder()
+	h.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/", nil))
+	assert.Equal(t, http.StatusOK, head.Code)
+	assert.Equal(t, rec.Header().Get("Content-Length"), head.Header().Get("Content-Length"))
+	assert.Zero(t, head.Body.Len())
+
+	// Páginas com </body> recebem o script antes do fechamento
+	require.NoError(t, os.WriteFile(filepath.Join(root, "page.html"), []byte("<html><BODY>oi</BODY></html>"), 0644))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/page.html", nil))
+	assert.Equal(t, "<html><BODY>oi"+liveReloadTag+"</BODY></html>", rec.Body.String())
+
+	// Outros tipos de arquivo não são alterados
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello.txt", nil))
+	assert.NotContains(t, rec.Body.String(), liveReloadTag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app.js", nil))
+	head = httptest.NewRecorder()
+	h.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/app.js", nil))
+	assert.Equal(t, http.StatusOK, head.Code)
+	assert.Equal(t, rec.Header().Get("Content-Length"), head.Header().Get("Content-Length"))
+	assert.Zero(t, head.Body.Len())
+}
+
+func TestLiveReloadWatchPublishes(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "node_modules"), 0755))
+	l, err := NewLiveReload(root, DefaultWatchIgnore, true)
+	require.NoError(t, err)
+	changes := make(chan string, 8)
+	l.OnChange = func(files []string, event string) { changes <- event }
+
+	mux := http.NewServeMux()
+	l.Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	done := make(chan struct{})
+	defer close(done)
+	require.NoError(t, l.Watch(done))
+
+	resp, err := http.Get(srv.URL + LiveReloadEventsPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Eventually(t, func() bool { return l.Clients() == 1 }, time.Second, 10*time.Millisecond)
+	events := bufio.NewReader(resp.Body)
+
+	wait := func() string {
+		t.Helper()
+		select {
+		case event := <-changes:
+			return event
+		case <-time.After(3 * time.Second):
+			t.Fatal("alteração não foi notificada")
+			return ""
+		}
+	}
+
+	// Subdiretórios criados depois do início também são observados
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "css"), 0755))
+	assert.Equal(t, "reload", wait())
+	assert.Contains(t, readSSEEvents(t, events, 1)[0], "event: reload\n")
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "css", "site.css"), []byte("body{}"), 0644))
+	assert.Equal(t, "css", wait())
+	assert.Contains(t, readSSEEvents(t, events, 1)[0], `data: ["/css/site.css"]`)
+
+	// Arquivos ignorados não geram eventos
+	require.NoError(t, os.WriteFile(filepath.Join(root, "node_modules", "x.js"), nil, 0644))
+	select {
+	case event := <-changes:
+		t.Fatalf("evento inesperado: %s", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestLiveReloadNotifyWithoutCSSHotSwap(t *testing.T) {
+	l, err := NewLiveReload(t.TempDir(), nil, false)
+	require.NoError(t, err)
+	var got string
+	l.OnChange = func(files []string, event string) { got = event }
+	l.Notify([]string{"site.css"})
+	assert.Equal(t, "reload", got)
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Prefixo dos nomes das métricas expostas pelo servidor
+const metricsNamespace = "bast"
+
+// DefaultLatencyBuckets limites (em segundos) do histograma de latência
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsCollector escreve métricas adicionais na exposição de /metrics
+type MetricsCollector func(w *MetricsWriter)
+
+// requestKey agrupa contadores por rota, método e status
+type requestKey struct {
+	route  string
+	method string
+	status int
+}
+
+// latencyKey agrupa o histograma por rota e método
+type latencyKey struct {
+	route  string
+	method string
+}
+
+// histogram histograma cumulativo no formato Prometheus
+type histogram struct {
+	counts []uint64 // uma posição por bucket, não cumulativa
+	count  uint64
+	sum    float64
+}
+
+// Metrics coleta métricas HTTP e as expõe no formato texto do Prometheus
+type Metrics struct {
+	buckets    []float64
+	start      time.Time
+	inFlight   atomic.Int64
+	mu         sync.Mutex
+	requests   map[requestKey]uint64
+	latency    map[latencyKey]*histogram
+	bytes      map[string]uint64
+	collectors []MetricsCollector
+}
+
+// NewMetrics cria um coletor de métricas com os buckets de latência padrão
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:  DefaultLatencyBuckets,
+		start:    time.Now(),
+		requests: make(map[requestKey]uint64),
+		latency:  make(map[latencyKey]*histogram),
+		bytes:    make(map[string]uint64),
+	}
+}
+
+// AddCollector registra métricas adicionais incluídas em cada exposição
+func (m *Metrics) AddCollector(c MetricsCollector) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collectors = append(m.collectors, c)
+}
+
+// Middleware mede cada requisição; a rota é o padrão do ServeMux que atendeu a
+// requisição, o que mantém a cardinalidade limitada
+func (m *Metrics) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.inFlight.Add(1)
+			defer m.inFlight.Add(-1)
+
+			start := time.Now()
+			rec := NewResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+			m.Observe(r.Pattern, r.Method, rec.Status(), rec.Bytes(), time.Since(start))
+		})
+	}
+}
+
+// Observe registra uma requisição concluída
+func (m *Metrics) Observe(route, method string, status int, bytes int64, d time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{route, method, status}]++
+	m.bytes[route] += uint64(bytes)
+
+	key := latencyKey{route, method}
+	h := m.latency[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[key] = h
+	}
+	seconds := d.Seconds()
+	h.count++
+	h.sum += seconds
+	for i, le := range m.buckets {
+		if seconds <= le {
+			h.counts[i]++
+			break
+		}
+	}
+}
+
+// Handler retorna o handler que expõe as métricas
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.Write(w)
+	})
+}
+
+// Write escreve todas as métricas no formato texto do Prometheus
+func (m *Metrics) Write(out io.Writer) {
+	w := &MetricsWriter{w: out}
+
+	m.mu.Lock()
+	m.writeHTTP(w)
+	collectors := append([]MetricsCollector(nil), m.collectors...)
+	m.mu.Unlock()
+
+	writeRuntime(w, m.start)
+	for _, c := range collectors {
+		c(w)
+	}
+}
+
+// writeHTTP escreve as métricas de requisições; requer m.mu
+func (m *Metrics) writeHTTP(w *MetricsWriter) {
+	name := metricsNamespace + "_http_requests_total"
+	w.Header(name, "counter", "Total de requisições HTTP por rota, método e status.")
+	reqKeys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		reqKeys = append(reqKeys, k)
+	}
+	sort.Slice(reqKeys, func(i, j int) bool {
+		a, b := reqKeys[i], reqKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	for _, k := range reqKeys {
+		w.Sample(name, float64(m.requests[k]), "route", k.route, "method", k.method, "status", strconv.Itoa(k.status))
+	}
+
+	name = metricsNamespace + "_http_request_duration_seconds"
+	w.Header(name, "histogram", "Latência das requisições HTTP em segundos.")
+	latKeys := make([]latencyKey, 0, len(m.latency))
+	for k := range m.latency {
+		latKeys = append(latKeys, k)
+	}
+	sort.Slice(latKeys, func(i, j int) bool {
+		if latKeys[i].route != latKeys[j].route {
+			return latKeys[i].route < latKeys[j].route
+		}
+		return latKeys[i].method < latKeys[j].method
+	})
+	for _, k := range latKeys {
+		h := m.latency[k]
+		var cumulative uint64
+		for i, le := range m.buckets {
+			cumulative += h.counts[i]
+			w.Sample(name+"_bucket", float64(cumulative), "route", k.route, "method", k.method, "le", formatFloat(le))
+		}
+		w.Sample(name+"_bucket", float64(h.count), "route", k.route, "method", k.method, "le", "+Inf")
+		w.Sample(name+"_sum", h.sum, "route", k.route, "method", k.method)
+		w.Sample(name+"_count", float64(h.count), "route", k.route, "method", k.method)
+	}
+
+	name = metricsNamespace + "_http_response_bytes_total"
+	w.Header(name, "counter", "Total de bytes enviados no corpo das respostas por rota.")
+	routes := make([]string, 0, len(m.bytes))
+	for route := range m.bytes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	for _, route := range routes {
+		w.Sample(name, float64(m.bytes[route]), "route", route)
+	}
+
+	name = metricsNamespace + "_http_requests_in_flight"
+	w.Header(name, "gauge", "Requisições HTTP em andamento.")
+	w.Sample(name, float64(m.inFlight.Load()))
+}
+
+// writeRuntime escreve as estatísticas do runtime Go
+func writeRuntime(w *MetricsWriter, start time.Time) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	w.Header("go_info", "gauge", "Versão do Go.")
+	w.Sample("go_info", 1, "version", runtime.Version())
+	w.Header("go_goroutines", "gauge", "Quantidade de goroutines.")
+	w.Sample("go_goroutines", float64(runtime.NumGoroutine()))
+	w.Header("go_memstats_alloc_bytes", "gauge", "Bytes alocados e ainda em uso.")
+	w.Sample("go_memstats_alloc_bytes", float64(mem.Alloc))
+	w.Header("go_memstats_sys_bytes", "gauge", "Bytes obtidos do sistema.")
+	w.Sample("go_memstats_sys_bytes", float64(mem.Sys))
+	w.Header("go_memstats_heap_objects", "gauge", "Objetos alocados no heap.")
+	w.Sample("go_memstats_heap_objects", float64(mem.HeapObjects))
+	w.Header("go_memstats_mallocs_total", "counter", "Total de alocações.")
+	w.Sample("go_memstats_mallocs_total", float64(mem.Mallocs))
+	w.Header("go_memstats_frees_total", "counter", "Total de liberações.")
+	w.Sample("go_memstats_frees_total", float64(mem.Frees))
+	w.Header("go_memstats_gc_cycles_total", "counter", "Ciclos de coleta de lixo concluídos.")
+	w.Sample("go_memstats_gc_cycles_total", float64(mem.NumGC))
+	w.Header("process_start_time_seconds", "gauge", "Início do processo em segundos desde a época Unix.")
+	w.Sample("process_start_time_seconds", float64(start.Unix()))
+}
+
+// MetricsWriter escreve amostras no formato texto do Prometheus
+type MetricsWriter struct {
+	w io.Writer
+}
+
+// Header escreve as linhas HELP e TYPE de uma métrica
+func (w *MetricsWriter) Header(name, typ, help string) {
+	fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// Sample escreve uma amostra; labels são pares nome, valor
+func (w *MetricsWriter) Sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		b.WriteByte('}')
+	}
+	fmt.Fprintf(w.w, "%s %s\n", b.String(), formatFloat(value))
+}
+
+// escapeLabel escapa barras, aspas e quebras de linha em valores de label
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatFloat formata valores como o Prometheus espera
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
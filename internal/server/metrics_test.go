@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := NewMetrics()
+	mux := http.NewServeMux()
+	mux.Handle("GET /bule/{id}", teapot())
+	mux.Handle("/metrics", m.Handler())
+	h := Chain(mux, RequestID(), m.Middleware())
+
+	for i := 0; i < 3; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bule/1", nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nada", nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, body, `bast_http_requests_total{route="GET /bule/{id}",method="GET",status="418"} 3`)
+	assert.Contains(t, body, `bast_http_requests_total{route="unmatched",method="GET",status="404"} 1`)
+	assert.Contains(t, body, `bast_http_request_duration_seconds_bucket{route="GET /bule/{id}",method="GET",le="+Inf"} 3`)
+	assert.Contains(t, body, `bast_http_request_duration_seconds_count{route="GET /bule/{id}",method="GET"} 3`)
+	assert.Contains(t, body, `bast_http_response_bytes_total{route="GET /bule/{id}"} 12`)
+	// A própria requisição a /metrics está em andamento
+	assert.Contains(t, body, "bast_http_requests_in_flight 1\n")
+	assert.Contains(t, body, "# TYPE go_goroutines gauge")
+	assert.Contains(t, body, "go_memstats_alloc_bytes ")
+}
+
+func TestMetricsHistogramBuckets(t *testing.T) {
+	m := NewMetrics()
+	m.Observe("/", "GET", 200, 0, 20*time.Millisecond)
+	m.Observe("/", "GET", 200, 0, 3*time.Second)
+
+	var b strings.Builder
+	m.Write(&b)
+	out := b.String()
+	assert.Contains(t, out, `bast_http_request_duration_seconds_bucket{route="/",method="GET",le="0.01"} 0`)
+	assert.Contains(t, out, `bast_http_request_duration_seconds_bucket{route="/",method="GET",le="0.025"} 1`)
+	assert.Contains(t, out, `bast_http_request_duration_seconds_bucket{route="/",method="GET",le="5"} 2`)
+	assert.Contains(t, out, `bast_http_request_duration_seconds_sum{route="/",method="GET"} 3.02`)
+}
+
+func TestMetricsCollector(t *testing.T) {
+	m := NewMetrics()
+	m.AddCollector(func(w *MetricsWriter) {
+		w.Header("bast_extra", "gauge", "Métrica extra.")
+		w.Sample("bast_extra", 2, "nome", `a"b`)
+	})
+	var b strings.Builder
+	m.Write(&b)
+	assert.Contains(t, b.String(), `bast_extra{nome="a\"b"} 2`)
+}
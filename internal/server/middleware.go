@@ -0,0 +1,201 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formatos de log de acesso suportados
+const (
+	AccessLogStructured = "structured"
+	AccessLogCombined   = "combined"
+	AccessLogOff        = "off"
+)
+
+// RequestIDHeader cabeçalho usado para propagar o ID da requisição
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware envolve um http.Handler adicionando comportamento
+type Middleware func(http.Handler) http.Handler
+
+// Chain aplica os middlewares ao handler; o primeiro da lista é o mais externo
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			h = mws[i](h)
+		}
+	}
+	return h
+}
+
+// requestIDKey chave do ID da requisição no contexto
+type requestIDKey struct{}
+
+// RequestIDFromContext retorna o ID da requisição guardado no contexto
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID reaproveita o cabeçalho X-Request-ID recebido ou gera um novo,
+// devolvendo-o na resposta e guardando-o no contexto
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" || len(id) > 128 {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		})
+	}
+}
+
+// newRequestID gera um ID aleatório em hexadecimal
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// AccessLog registra uma entrada por requisição; no formato estruturado usa o
+// logger (respeitando o formato text/json configurado) e no formato combined
+// escreve linhas no padrão Apache em out
+func AccessLog(log *logrus.Logger, format string, out io.Writer) Middleware {
+	if format == AccessLogOff {
+		return nil
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := NewResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+			duration := time.Since(start)
+
+			if format == AccessLogCombined {
+				fmt.Fprintln(out, combinedLogLine(r, rec, start))
+				return
+			}
+
+			log.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"path":        r.URL.RequestURI(),
+				"status":      rec.Status(),
+				"bytes":       rec.Bytes(),
+				"duration_ms": durationMS(duration),
+				"remote_addr": r.RemoteAddr,
+				"user_agent":  r.UserAgent(),
+				"request_id":  RequestIDFromContext(r.Context()),
+			}).Info("requisição HTTP")
+		})
+	}
+}
+
+// combinedLogLine formata a requisição no Apache Combined Log Format
+func combinedLogLine(r *http.Request, rec *ResponseRecorder, start time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+	size := "-"
+	if rec.Bytes() > 0 {
+		size = fmt.Sprintf("%d", rec.Bytes())
+	}
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s %q %q",
+		host, user, start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.URL.RequestURI(), r.Proto, rec.Status(), size,
+		orDash(r.Referer()), orDash(r.UserAgent()))
+}
+
+// orDash substitui valores vazios por "-"
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// ResponseRecorder guarda o status e a quantidade de bytes escritos na resposta,
+// preservando Flush e Hijack do ResponseWriter original
+type ResponseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// NewResponseRecorder envolve o ResponseWriter informado
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w}
+}
+
+// Status retorna o status enviado (200 se nenhum foi definido explicitamente)
+func (r *ResponseRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Bytes retorna a quantidade de bytes do corpo escritos
+func (r *ResponseRecorder) Bytes() int64 {
+	return r.bytes
+}
+
+// WriteHeader registra o status antes de repassá-lo
+func (r *ResponseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write contabiliza os bytes escritos
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush repassa o Flush quando suportado
+func (r *ResponseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack repassa o Hijack quando suportado
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijack não suportado")
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap permite que http.ResponseController acesse o ResponseWriter original
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
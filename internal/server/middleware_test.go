@@ -0,0 +1,72 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func teapot() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("chá"))
+	})
+}
+
+func TestAccessLogStructured(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	h := Chain(teapot(), RequestID(), AccessLog(log, AccessLogStructured, &buf))
+	req := httptest.NewRequest(http.MethodGet, "/bule?x=1", nil)
+	req.Header.Set("User-Agent", "teste")
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/bule?x=1", entry["path"])
+	assert.Equal(t, float64(http.StatusTeapot), entry["status"])
+	assert.Equal(t, float64(len("chá")), entry["bytes"])
+	assert.Equal(t, "teste", entry["user_agent"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Contains(t, entry, "duration_ms")
+	assert.Contains(t, entry, "remote_addr")
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	var buf bytes.Buffer
+	h := Chain(teapot(), RequestID(), AccessLog(logrus.New(), AccessLogCombined, &buf))
+
+	req := httptest.NewRequest(http.MethodPost, "/bule", nil)
+	req.SetBasicAuth("maria", "segredo")
+	req.Header.Set("Referer", "http://origem")
+	req.Header.Set("User-Agent", "teste")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	pattern := `^192\.0\.2\.1 - maria \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "POST /bule HTTP/1\.1" 418 4 "http://origem" "teste"\n$`
+	assert.Regexp(t, regexp.MustCompile(pattern), buf.String())
+	assert.NotEmpty(t, rec.Header().Get(RequestIDHeader))
+}
+
+func TestAccessLogOff(t *testing.T) {
+	assert.Nil(t, AccessLog(logrus.New(), AccessLogOff, nil))
+	h := Chain(teapot(), AccessLog(logrus.New(), AccessLogOff, nil))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
@@ -0,0 +1,333 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// MockRoute rota declarada no arquivo de rotas do servidor mock
+type MockRoute struct {
+	Method   string            `yaml:"method" json:"method,omitempty"`
+	Path     string            `yaml:"path" json:"path"`
+	Status   int               `yaml:"status" json:"status"`
+	Headers  map[string]string `yaml:"headers" json:"headers,omitempty"`
+	Body     string            `yaml:"body" json:"body,omitempty"`
+	File     string            `yaml:"file" json:"file,omitempty"`
+	Template string            `yaml:"template" json:"template,omitempty"`
+	Delay    string            `yaml:"delay" json:"delay,omitempty"`
+}
+
+// mockFile estrutura do arquivo de rotas
+type mockFile struct {
+	Routes []MockRoute `yaml:"routes"`
+}
+
+// MockTemplateData dados disponíveis nos templates de resposta
+type MockTemplateData struct {
+	Method  string
+	Path    string
+	Params  map[string]string
+	Query   map[string]string
+	Headers map[string]string
+	Body    string
+}
+
+// paramPattern extrai os nomes de parâmetros de um padrão de caminho ({id} ou {rest...})
+var paramPattern = regexp.MustCompile(`\{([^}.]+)(?:\.\.\.)?\}`)
+
+// MockHandler responde requisições a partir de rotas declaradas em arquivo YAML,
+// recarregando o arquivo quando ele muda
+type MockHandler struct {
+	// OnReload é chamado após cada recarga automática do arquivo
+	OnReload func(routes int, err error)
+
+	path string
+
+	mu     sync.RWMutex
+	mux    *http.ServeMux
+	routes []MockRoute
+}
+
+// NewMockHandler cria um MockHandler e carrega as rotas do arquivo informado
+func NewMockHandler(path string) (*MockHandler, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao resolver arquivo de rotas %s: %w", path, err)
+	}
+	m := &MockHandler{path: abs}
+	if err := m.Load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Path retorna o caminho absoluto do arquivo de rotas
+func (m *MockHandler) Path() string {
+	return m.path
+}
+
+// Routes retorna uma cópia das rotas carregadas
+func (m *MockHandler) Routes() []MockRoute {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	routes := make([]MockRoute, len(m.routes))
+	copy(routes, m.routes)
+	return routes
+}
+
+// Load lê o arquivo de rotas e substitui as rotas atuais; em caso de erro as
+// rotas anteriores são mantidas
+func (m *MockHandler) Load() error {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo de rotas: %w", err)
+	}
+
+	var file mockFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("erro ao interpretar arquivo de rotas: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	baseDir := filepath.Dir(m.path)
+	for i := range file.Routes {
+		route := &file.Routes[i]
+		h, err := newMockRouteHandler(route, baseDir)
+		if err != nil {
+			return fmt.Errorf("rota %d (%s): %w", i+1, route.Path, err)
+		}
+		if err := registerPattern(mux, routePattern(route), h); err != nil {
+			return fmt.Errorf("rota %d (%s): %w", i+1, route.Path, err)
+		}
+	}
+
+	m.mu.Lock()
+	m.mux = mux
+	m.routes = file.Routes
+	m.mu.Unlock()
+	return nil
+}
+
+// Watch observa o arquivo de rotas e o recarrega a cada alteração até que
+// done seja fechado
+func (m *MockHandler) Watch(done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("erro ao criar observador de arquivos: %w", err)
+	}
+	// Observa o diretório para acompanhar editores que substituem o arquivo
+	if err := watcher.Add(filepath.Dir(m.path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("erro ao observar %s: %w", m.path, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == m.path && !event.Has(fsnotify.Chmod) {
+					debounce = time.After(100 * time.Millisecond)
+				}
+			case <-debounce:
+				debounce = nil
+				err := m.Load()
+				if m.OnReload != nil {
+					m.OnReload(len(m.Routes()), err)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *MockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.RLock()
+	mux := m.mux
+	m.mu.RUnlock()
+	mux.ServeHTTP(w, r)
+}
+
+// RoutesHandler retorna um handler que lista as rotas carregadas em JSON
+func (m *MockHandler) RoutesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"file":   m.path,
+			"routes": m.Routes(),
+		})
+	})
+}
+
+// routePattern monta o padrão do ServeMux a partir do método e do caminho da rota
+func routePattern(route *MockRoute) string {
+	if route.Method == "" {
+		return route.Path
+	}
+	return strings.ToUpper(route.Method) + " " + route.Path
+}
+
+// registerPattern registra o padrão convertendo o panic do ServeMux em erro
+func registerPattern(mux *http.ServeMux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("padrão inválido: %v", r)
+		}
+	}()
+	mux.Handle(pattern, h)
+	return nil
+}
+
+// newMockRouteHandler cria o handler de uma rota, validando corpo, template e atraso
+func newMockRouteHandler(route *MockRoute, baseDir string) (http.Handler, error) {
+	if route.Path == "" {
+		return nil, errors.New("path é obrigatório")
+	}
+	if route.Status == 0 {
+		route.Status = http.StatusOK
+	}
+
+	sources := 0
+	for _, v := range []string{route.Body, route.File, route.Template} {
+		if v != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, errors.New("use apenas um entre body, file e template")
+	}
+
+	var delay time.Duration
+	if route.Delay != "" {
+		d, err := time.ParseDuration(route.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("delay inválido: %w", err)
+		}
+		delay = d
+	}
+
+	var tmpl *template.Template
+	if route.Template != "" {
+		t, err := template.New(route.Path).Parse(route.Template)
+		if err != nil {
+			return nil, fmt.Errorf("template inválido: %w", err)
+		}
+		tmpl = t
+	}
+
+	var file string
+	if route.File != "" {
+		file = route.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(baseDir, file)
+		}
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("arquivo de corpo inválido: %w", err)
+		}
+	}
+
+	params := paramNames(route.Path)
+	r := *route
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-req.Context().Done():
+				return
+			}
+		}
+
+		var body []byte
+		switch {
+		case tmpl != nil:
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, newMockTemplateData(req, params)); err != nil {
+				http.Error(w, fmt.Sprintf("erro ao executar template: %v", err), http.StatusInternalServerError)
+				return
+			}
+			body = buf.Bytes()
+		case file != "":
+			data, err := os.ReadFile(file) //nolint:gosec // arquivo declarado no arquivo de rotas
+			if err != nil {
+				http.Error(w, fmt.Sprintf("erro ao ler arquivo: %v", err), http.StatusInternalServerError)
+				return
+			}
+			body = data
+		default:
+			body = []byte(r.Body)
+		}
+
+		for k, v := range r.Headers {
+			w.Header().Set(k, v)
+		}
+		if w.Header().Get("Content-Type") == "" && len(body) > 0 {
+			w.Header().Set("Content-Type", http.DetectContentType(body))
+		}
+		w.WriteHeader(r.Status)
+		if req.Method != http.MethodHead {
+			_, _ = w.Write(body)
+		}
+	}), nil
+}
+
+// newMockTemplateData reúne parâmetros de caminho, query, cabeçalhos e corpo da requisição
+func newMockTemplateData(r *http.Request, params []string) MockTemplateData {
+	data := MockTemplateData{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Params:  make(map[string]string, len(params)),
+		Query:   make(map[string]string),
+		Headers: make(map[string]string),
+	}
+	for _, name := range params {
+		data.Params[name] = r.PathValue(name)
+	}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			data.Query[k] = v[0]
+		}
+	}
+	for k := range r.Header {
+		data.Headers[k] = r.Header.Get(k)
+	}
+	if r.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err == nil {
+			data.Body = string(body)
+		}
+	}
+	return data
+}
+
+// paramNames retorna os nomes dos parâmetros declarados no caminho
+func paramNames(path string) []string {
+	matches := paramPattern.FindAllStringSubmatch(path, -1)
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m[1])
+	}
+	return names
+}
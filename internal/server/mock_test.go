@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockRoutesYAML = `routes:
+  - method: GET
+    path: /users/{id}
+    headers:
+      Content-Type: application/json
+    template: '{"id": "{{.Params.id}}", "q": "{{.Query.q}}"}'
+  - method: POST
+    path: /users
+    status: 201
+    body: created
+  - path: /files/{rest...}
+    file: body.txt
+    delay: 10ms
+`
+
+// writeMockRoutes grava o arquivo de rotas e um arquivo de corpo em um diretório temporário
+func writeMockRoutes(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "body.txt"), []byte("conteúdo do arquivo"), 0644))
+	path := filepath.Join(dir, "mock.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestMockHandlerRoutes(t *testing.T) {
+	m, err := NewMockHandler(writeMockRoutes(t, mockRoutesYAML))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42?q=abc", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id": "42", "q": "abc"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "created", rec.Body.String())
+
+	start := time.Now()
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/a/b.txt", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "conteúdo do arquivo", rec.Body.String())
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestMockHandlerInvalidFileKeepsRoutes(t *testing.T) {
+	path := writeMockRoutes(t, mockRoutesYAML)
+	m, err := NewMockHandler(path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("routes:\n  - path: /x\n    delay: nope\n"), 0644))
+	assert.Error(t, m.Load())
+	assert.Len(t, m.Routes(), 3)
+
+	require.NoError(t, os.WriteFile(path, []byte("routes:\n  - path: /a\n  - path: /a\n"), 0644))
+	assert.Error(t, m.Load())
+	assert.Len(t, m.Routes(), 3)
+}
+
+func TestMockHandlerWatchReloads(t *testing.T) {
+	path := writeMockRoutes(t, mockRoutesYAML)
+	m, err := NewMockHandler(path)
+	require.NoError(t, err)
+
+	reloaded := make(chan int, 4)
+	m.OnReload = func(routes int, err error) {
+		if err == nil {
+			reloaded <- routes
+		}
+	}
+	done := make(chan struct{})
+	defer close(done)
+	require.NoError(t, m.Watch(done))
+
+	require.NoError(t, os.WriteFile(path, []byte("routes:\n  - path: /only\n    body: novo\n"), 0644))
+
+	select {
+	case n := <-reloaded:
+		assert.Equal(t, 1, n)
+	case <-time.After(3 * time.Second):
+		t.Fatal("arquivo de rotas não foi recarregado")
+	}
+
+	rec := httptest.NewRecorder()
+	m.RoutesHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__routes", nil))
+	var listing struct {
+		Routes []MockRoute `json:"routes"`
+	}
+	require.NoError(t, json.NewDecoder(strings.NewReader(rec.Body.String())).Decode(&listing))
+	require.Len(t, listing.Routes, 1)
+	assert.Equal(t, "/only", listing.Routes[0].Path)
+}
@@ -0,0 +1,391 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Estratégias de balanceamento entre upstreams de uma mesma rota
+const (
+	ProxyRoundRobin = "round-robin"
+	ProxyFailover   = "failover"
+)
+
+// maxRetryBody tamanho máximo do corpo guardado para repetir a requisição em
+// outro upstream; corpos maiores ou sem tamanho conhecido não são repetidos
+const maxRetryBody = 1 << 20
+
+// ProxyOptions opções do proxy reverso
+type ProxyOptions struct {
+	Strategy        string        // round-robin ou failover
+	StripPrefix     bool          // remove o prefixo da rota antes de repassar
+	HealthPath      string        // caminho verificado em cada upstream ("" desabilita)
+	HealthInterval  time.Duration // intervalo entre verificações de saúde
+	RequestHeaders  []string      // "Nome: valor" define, "-Nome" remove
+	ResponseHeaders []string      // "Nome: valor" define, "-Nome" remove
+}
+
+// ProxyUpstreamStatus estado de um upstream exposto em /__proxy
+type ProxyUpstreamStatus struct {
+	URL     string `json:"url"`
+	Healthy bool   `json:"healthy"`
+}
+
+// ProxyRouteStatus estado de uma rota exposto em /__proxy
+type ProxyRouteStatus struct {
+	Prefix    string                `json:"prefix"`
+	Directory string                `json:"directory,omitempty"`
+	Upstreams []ProxyUpstreamStatus `json:"upstreams,omitempty"`
+}
+
+// upstream destino HTTP de uma rota com seu estado de saúde
+type upstream struct {
+	target  *url.URL
+	proxy   *httputil.ReverseProxy
+	healthy atomic.Bool
+}
+
+// proxyRoute rota do proxy: um prefixo servido por upstreams ou por um diretório
+type proxyRoute struct {
+	prefix    string
+	upstreams []*upstream
+	static    *StaticHandler
+	next      atomic.Uint64
+}
+
+// Proxy encaminha requisições para upstreams conforme o prefixo do caminho
+type Proxy struct {
+	// OnHealthChange é chamado quando um upstream muda de estado
+	OnHealthChange func(target string, healthy bool)
+
+	routes []*proxyRoute
+	opts   ProxyOptions
+	client *http.Client
+}
+
+// NewProxy cria um Proxy a partir de especificações "prefixo=destino[,destino...]",
+// onde destino é uma URL http(s) ou um diretório local
+func NewProxy(specs []string, opts ProxyOptions) (*Proxy, error) {
+	if opts.Strategy == "" {
+		opts.Strategy = ProxyRoundRobin
+	}
+	if opts.Strategy != ProxyRoundRobin && opts.Strategy != ProxyFailover {
+		return nil, fmt.Errorf("estratégia de proxy inválida '%s': use %s ou %s", opts.Strategy, ProxyRoundRobin, ProxyFailover)
+	}
+	reqHeaders, err := parseHeaderRules(opts.RequestHeaders)
+	if err != nil {
+		return nil, err
+	}
+	respHeaders, err := parseHeaderRules(opts.ResponseHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proxy{opts: opts, client: &http.Client{Timeout: 5 * time.Second}}
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		route, err := p.parseRoute(spec, reqHeaders, respHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if seen[route.prefix] {
+			return nil, fmt.Errorf("prefixo de proxy duplicado: %s", route.prefix)
+		}
+		seen[route.prefix] = true
+		p.routes = append(p.routes, route)
+	}
+	if len(p.routes) == 0 {
+		return nil, fmt.Errorf("nenhuma rota de proxy informada")
+	}
+
+	// Prefixos mais longos têm prioridade
+	sort.Slice(p.routes, func(i, j int) bool {
+		return len(p.routes[i].prefix) > len(p.routes[j].prefix)
+	})
+	return p, nil
+}
+
+// parseRoute interpreta uma especificação "prefixo=destino[,destino...]"
+func (p *Proxy) parseRoute(spec string, reqHeaders, respHeaders []headerRule) (*proxyRoute, error) {
+	prefix, targets, ok := strings.Cut(spec, "=")
+	if !ok || strings.TrimSpace(targets) == "" {
+		return nil, fmt.Errorf("rota de proxy inválida '%s': use prefixo=destino", spec)
+	}
+	prefix = "/" + strings.Trim(strings.TrimSpace(prefix), "/")
+	route := &proxyRoute{prefix: prefix}
+
+	for _, raw := range strings.Split(targets, ",") {
+		raw = strings.TrimSpace(raw)
+		if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+			target, err := url.Parse(raw)
+			if err != nil || target.Host == "" {
+				return nil, fmt.Errorf("URL de upstream inválida '%s'", raw)
+			}
+			route.upstreams = append(route.upstreams, p.newUpstream(route, target, reqHeaders, respHeaders))
+			continue
+		}
+		if route.static != nil || len(route.upstreams) > 0 {
+			return nil, fmt.Errorf("rota %s: um diretório não pode ser combinado com outros destinos", prefix)
+		}
+		static, err := NewStaticHandler(raw, StaticOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("rota %s: %w", prefix, err)
+		}
+		route.static = static
+	}
+	if route.static != nil && len(route.upstreams) > 0 {
+		return nil, fmt.Errorf("rota %s: um diretório não pode ser combinado com outros destinos", prefix)
+	}
+	return route, nil
+}
+
+// newUpstream cria o proxy reverso de um upstream aplicando as regras de cabeçalho
+func (p *Proxy) newUpstream(route *proxyRoute, target *url.URL, reqHeaders, respHeaders []headerRule) *upstream {
+	u := &upstream{target: target}
+	u.healthy.Store(true)
+	u.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if p.opts.StripPrefix && route.prefix != "/" {
+				pr.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(pr.Out.URL.Path, route.prefix), "/")
+				pr.Out.URL.RawPath = ""
+			}
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			applyHeaderRules(pr.Out.Header, reqHeaders)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			applyHeaderRules(resp.Header, respHeaders)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if r.Context().Err() == nil {
+				p.setHealth(u, false)
+			}
+			// Nada foi enviado ao cliente: ServeHTTP tenta o próximo upstream
+			if attempt, ok := r.Context().Value(proxyAttemptKey{}).(*proxyAttempt); ok && attempt.retry && r.Context().Err() == nil {
+				attempt.failed = true
+				return
+			}
+			http.Error(w, fmt.Sprintf("erro ao acessar upstream %s: %v", target.Host, err), http.StatusBadGateway)
+		},
+	}
+	return u
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := p.match(r.URL.Path)
+	if route == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if route.static != nil {
+		if route.prefix == "/" {
+			route.static.ServeHTTP(w, r)
+			return
+		}
+		http.StripPrefix(route.prefix, route.static).ServeHTTP(w, r)
+		return
+	}
+
+	candidates := route.candidates(p.opts.Strategy)
+	body, replayable := retryBody(r)
+	if !replayable || len(candidates) == 1 {
+		candidates[0].proxy.ServeHTTP(w, r)
+		return
+	}
+	// Requisições idempotentes com corpo repetível seguem para o próximo
+	// upstream quando o atual falha antes de responder
+	for i, u := range candidates {
+		attempt := &proxyAttempt{retry: i < len(candidates)-1}
+		req := r.WithContext(context.WithValue(r.Context(), proxyAttemptKey{}, attempt))
+		if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		u.proxy.ServeHTTP(w, req)
+		if !attempt.failed {
+			return
+		}
+	}
+}
+
+// proxyAttemptKey chave do contexto com a tentativa atual de ServeHTTP
+type proxyAttemptKey struct{}
+
+// proxyAttempt informa ao ErrorHandler se a falha pode ser repetida em outro
+// upstream e registra que ela ocorreu
+type proxyAttempt struct {
+	retry  bool
+	failed bool
+}
+
+// retryBody verifica se a requisição pode ser repetida em outro upstream:
+// método idempotente e corpo vazio ou pequeno o bastante para ser guardado
+func retryBody(r *http.Request) ([]byte, bool) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		return nil, false
+	}
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil, true
+	}
+	if r.ContentLength < 0 || r.ContentLength > maxRetryBody {
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, r.ContentLength))
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// match retorna a rota de maior prefixo que casa com o caminho
+func (p *Proxy) match(path string) *proxyRoute {
+	for _, route := range p.routes {
+		if route.prefix == "/" || path == route.prefix || strings.HasPrefix(path, route.prefix+"/") {
+			return route
+		}
+	}
+	return nil
+}
+
+// candidates ordena os upstreams para a requisição conforme a estratégia: os
+// saudáveis primeiro e, depois, os fora do ar, na ordem normal da estratégia
+func (r *proxyRoute) candidates(strategy string) []*upstream {
+	n := len(r.upstreams)
+	start := 0
+	if strategy == ProxyRoundRobin {
+		start = int((r.next.Add(1) - 1) % uint64(n))
+	}
+	out := make([]*upstream, 0, n)
+	var down []*upstream
+	for i := 0; i < n; i++ {
+		u := r.upstreams[(start+i)%n]
+		if u.healthy.Load() {
+			out = append(out, u)
+		} else {
+			down = append(down, u)
+		}
+	}
+	return append(out, down...)
+}
+
+// setHealth atualiza o estado do upstream, notificando mudanças
+func (p *Proxy) setHealth(u *upstream, healthy bool) {
+	if u.healthy.Swap(healthy) != healthy && p.OnHealthChange != nil {
+		p.OnHealthChange(u.target.String(), healthy)
+	}
+}
+
+// StartHealthChecks verifica periodicamente o caminho de saúde de cada upstream
+// até que o contexto seja cancelado
+func (p *Proxy) StartHealthChecks(ctx context.Context) {
+	if p.opts.HealthPath == "" || p.opts.HealthInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(p.opts.HealthInterval)
+		defer ticker.Stop()
+		for {
+			p.CheckHealth(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CheckHealth executa uma rodada de verificações de saúde em todos os upstreams
+func (p *Proxy) CheckHealth(ctx context.Context) {
+	for _, route := range p.routes {
+		for _, u := range route.upstreams {
+			p.setHealth(u, p.probe(ctx, u))
+		}
+	}
+}
+
+// probe considera o upstream saudável se o caminho de saúde responder 2xx ou 3xx
+func (p *Proxy) probe(ctx context.Context, u *upstream) bool {
+	target := *u.target
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + strings.TrimPrefix(p.opts.HealthPath, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), http.NoBody)
+	if err != nil {
+		return false
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < http.StatusBadRequest
+}
+
+// Status retorna o estado atual das rotas e upstreams
+func (p *Proxy) Status() []ProxyRouteStatus {
+	out := make([]ProxyRouteStatus, 0, len(p.routes))
+	for _, route := range p.routes {
+		status := ProxyRouteStatus{Prefix: route.prefix}
+		if route.static != nil {
+			status.Directory = route.static.Root()
+		}
+		for _, u := range route.upstreams {
+			status.Upstreams = append(status.Upstreams, ProxyUpstreamStatus{URL: u.target.String(), Healthy: u.healthy.Load()})
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+// StatusHandler retorna um handler que expõe Status em JSON
+func (p *Proxy) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, p.Status())
+	})
+}
+
+// headerRule regra de reescrita de cabeçalho
+type headerRule struct {
+	name   string
+	value  string
+	remove bool
+}
+
+// parseHeaderRules interpreta regras "Nome: valor" (define) e "-Nome" (remove)
+func parseHeaderRules(rules []string) ([]headerRule, error) {
+	out := make([]headerRule, 0, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if strings.HasPrefix(rule, "-") {
+			out = append(out, headerRule{name: strings.TrimSpace(rule[1:]), remove: true})
+			continue
+		}
+		name, value, ok := strings.Cut(rule, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("regra de cabeçalho inválida '%s': use 'Nome: valor' ou '-Nome'", rule)
+		}
+		out = append(out, headerRule{name: strings.TrimSpace(name), value: strings.TrimSpace(value)})
+	}
+	return out, nil
+}
+
+// applyHeaderRules aplica as regras de cabeçalho
+func applyHeaderRules(h http.Header, rules []headerRule) {
+	for _, rule := range rules {
+		if rule.remove {
+			h.Del(rule.name)
+		} else {
+			h.Set(rule.name, rule.value)
+		}
+	}
+}
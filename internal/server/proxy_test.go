@@ -0,0 +1,225 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namedUpstream responde com o nome do upstream e ecoa alguns dados da requisição
+func namedUpstream(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "upstream")
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Host", r.Host)
+		w.Header().Set("X-Env", r.Header.Get("X-Env"))
+		w.Header().Set("X-Forwarded", r.Header.Get("X-Forwarded-For"))
+		_, _ = io.WriteString(w, name)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func proxyGet(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestProxyPrefixRouting(t *testing.T) {
+	api := namedUpstream(t, "api")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("spa"), 0644))
+
+	p, err := NewProxy([]string{"/=" + dir, "/api=" + api.URL}, ProxyOptions{
+		RequestHeaders:  []string{"X-Env: dev"},
+		ResponseHeaders: []string{"-Server"},
+	})
+	require.NoError(t, err)
+
+	rec := proxyGet(t, p, "/api/users")
+	assert.Equal(t, "api", rec.Body.String())
+	assert.Equal(t, "/api/users", rec.Header().Get("X-Path"))
+	assert.Equal(t, strings.TrimPrefix(api.URL, "http://"), rec.Header().Get("X-Host"))
+	assert.Equal(t, "dev", rec.Header().Get("X-Env"))
+	assert.NotEmpty(t, rec.Header().Get("X-Forwarded"))
+	assert.Empty(t, rec.Header().Get("Server"))
+
+	// "/apix" não pertence ao prefixo /api
+	assert.Equal(t, http.StatusNotFound, proxyGet(t, p, "/apix").Code)
+	assert.Equal(t, "spa", proxyGet(t, p, "/").Body.String())
+}
+
+func TestProxyStripPrefix(t *testing.T) {
+	api := namedUpstream(t, "api")
+	p, err := NewProxy([]string{"/api=" + api.URL}, ProxyOptions{StripPrefix: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/users", proxyGet(t, p, "/api/users").Header().Get("X-Path"))
+	assert.Equal(t, "/", proxyGet(t, p, "/api").Header().Get("X-Path"))
+	assert.Equal(t, http.StatusNotFound, proxyGet(t, p, "/outro").Code)
+}
+
+func TestProxyRoundRobinAndFailover(t *testing.T) {
+	a := namedUpstream(t, "a")
+	b := namedUpstream(t, "b")
+
+	rr, err := NewProxy([]string{"/=" + a.URL + "," + b.URL}, ProxyOptions{})
+	require.NoError(t, err)
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, proxyGet(t, rr, "/").Body.String())
+	}
+	assert.Equal(t, []string{"a", "b", "a", "b"}, got)
+
+	// Upstream fora do ar é marcado e evitado nas próximas requisições
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	fo, err := NewProxy([]string{"/=" + deadURL + "," + b.URL}, ProxyOptions{Strategy: ProxyFailover})
+	require.NoError(t, err)
+	var changes []string
+	fo.OnHealthChange = func(target string, healthy bool) {
+		changes = append(changes, target)
+	}
+	// A requisição que encontra o upstream fora do ar é repetida no próximo
+	assert.Equal(t, "b", proxyGet(t, fo, "/").Body.String())
+	assert.Equal(t, "b", proxyGet(t, fo, "/").Body.String())
+	assert.Equal(t, []string{deadURL}, changes)
+}
+
+func TestProxyFailoverRetry(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+	var bodies []string
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		_, _ = io.WriteString(w, "b")
+	}))
+	defer b.Close()
+
+	newFailover := func() *Proxy {
+		p, err := NewProxy([]string{"/=" + deadURL + "," + b.URL}, ProxyOptions{Strategy: ProxyFailover})
+		require.NoError(t, err)
+		return p
+	}
+
+	// PUT com corpo é repetido com o mesmo corpo
+	rec := httptest.NewRecorder()
+	newFailover().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/item", strings.NewReader("dados")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "b", rec.Body.String())
+	assert.Equal(t, []string{"dados"}, bodies)
+
+	// POST não é idempotente: a falha chega ao cliente
+	rec = httptest.NewRecorder()
+	newFailover().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/item", strings.NewReader("dados")))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Len(t, bodies, 1)
+
+	// Todos fora do ar: o último erro é respondido
+	p, err := NewProxy([]string{"/=" + deadURL + "," + deadURL + "/x"}, ProxyOptions{Strategy: ProxyFailover})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, proxyGet(t, p, "/").Code)
+}
+
+func TestProxyHealthCheck(t *testing.T) {
+	var healthy atomic.Bool
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" && !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer up.Close()
+
+	p, err := NewProxy([]string{"/=" + up.URL}, ProxyOptions{HealthPath: "/health"})
+	require.NoError(t, err)
+
+	p.CheckHealth(context.Background())
+	assert.False(t, p.Status()[0].Upstreams[0].Healthy)
+
+	healthy.Store(true)
+	p.CheckHealth(context.Background())
+	assert.True(t, p.Status()[0].Upstreams[0].Healthy)
+}
+
+func TestProxyWebSocketUpgrade(t *testing.T) {
+	// Upstream que aceita o upgrade e ecoa uma linha pela conexão
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "upgrade esperado", http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		_ = rw.Flush()
+		line, _ := rw.ReadString('\n')
+		_, _ = rw.WriteString("eco: " + line)
+		_ = rw.Flush()
+	}))
+	defer up.Close()
+
+	p, err := NewProxy([]string{"/ws=" + up.URL}, ProxyOptions{})
+	require.NoError(t, err)
+	front := httptest.NewServer(Chain(p, RequestID()))
+	defer front.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(front.URL, "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: bast\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	_, err = io.WriteString(conn, "olá\n")
+	require.NoError(t, err)
+	line, err := br.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "eco: olá\n", line)
+}
+
+func TestNewProxyErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		specs []string
+		opts  ProxyOptions
+	}{
+		{nil, ProxyOptions{}},
+		{[]string{"/api"}, ProxyOptions{}},
+		{[]string{"/api=http://"}, ProxyOptions{}},
+		{[]string{"/=http://a", "/=http://b"}, ProxyOptions{}},
+		{[]string{"/=" + dir + ",http://a"}, ProxyOptions{}},
+		{[]string{"/=http://a"}, ProxyOptions{Strategy: "aleatório"}},
+		{[]string{"/=http://a"}, ProxyOptions{RequestHeaders: []string{"sem-valor"}}},
+	}
+	for _, tt := range tests {
+		_, err := NewProxy(tt.specs, tt.opts)
+		assert.Error(t, err, "%v", tt.specs)
+	}
+}
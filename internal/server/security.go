@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+)
+
+// SecurityHeaders adiciona os cabeçalhos de segurança configurados antes de chamar
+// o handler, que ainda pode sobrescrevê-los; retorna nil se desabilitado
+func SecurityHeaders(cfg config.SecurityHeadersConfig) Middleware {
+	if !cfg.Enabled {
+		return nil
+	}
+	headers := map[string]string{
+		"Content-Security-Policy": cfg.CSP,
+		"X-Content-Type-Options":  cfg.ContentTypeOptions,
+		"X-Frame-Options":         cfg.FrameOptions,
+		"Referrer-Policy":         cfg.ReferrerPolicy,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			for name, value := range headers {
+				if value != "" {
+					h.Set(name, value)
+				}
+			}
+			// HSTS só tem efeito (e só é permitido) em respostas HTTPS
+			if r.TLS != nil && cfg.HSTS != "" {
+				h.Set("Strict-Transport-Security", cfg.HSTS)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
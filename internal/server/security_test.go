@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	cfg := config.SecurityHeadersConfig{
+		Enabled:            true,
+		HSTS:               "max-age=60",
+		CSP:                "default-src 'self'",
+		ContentTypeOptions: "nosniff",
+	}
+	h := Chain(teapot(), SecurityHeaders(cfg))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "default-src 'self'", rec.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, rec.Header().Get("X-Frame-Options"))
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://bast/", nil))
+	assert.Equal(t, "max-age=60", rec.Header().Get("Strict-Transport-Security"))
+
+	assert.Nil(t, SecurityHeaders(config.SecurityHeadersConfig{}))
+}
+
+// inlineCode encontra scripts, estilos e manipuladores de evento embutidos no
+// HTML, bloqueados pela Content-Security-Policy padrão (default-src 'self')
+var inlineCode = regexp.MustCompile(`<script>|<style|\son[a-z]+=|\sstyle=`)
+
+// assertNoInlineCode verifica que a página funciona sob a CSP padrão
+func assertNoInlineCode(t *testing.T, page string) {
+	t.Helper()
+	assert.Empty(t, inlineCode.FindAllString(page, -1), "código embutido bloqueado pela CSP")
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Server envolve um http.Server e acompanha as requisições em andamento
+// para permitir um encerramento gracioso com relatório
+type Server struct {
+	// BeforeShutdown é chamado, se definido, quando o encerramento começa
+	BeforeShutdown func(inFlight int64)
+	// ConnLimiter limita, se definido, as conexões simultâneas aceitas
+	ConnLimiter *ConnLimiter
+
+	srv      *http.Server
+	inFlight atomic.Int64
+}
+
+// ShutdownReport resume o resultado do encerramento gracioso
+type ShutdownReport struct {
+	InFlight         int64         // requisições ativas quando o encerramento começou
+	Drained          int64         // requisições concluídas durante o período de graça
+	Remaining        int64         // requisições interrompidas ao atingir o prazo
+	DeadlineExceeded bool          // indica se o prazo de graça foi atingido
+	Duration         time.Duration // tempo total gasto no encerramento
+}
+
+// New cria um Server a partir de um http.Server já configurado
+func New(srv *http.Server) *Server {
+	s := &Server{srv: srv}
+	handler := srv.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	srv.Handler = s.track(handler)
+	return s
+}
+
+// InFlight retorna o número de requisições em andamento
+func (s *Server) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// Run escuta no endereço configurado e atende requisições até que o contexto
+// seja cancelado, encerrando graciosamente dentro do período de graça
+func (s *Server) Run(ctx context.Context, grace time.Duration) (*ShutdownReport, error) {
+	addr := s.srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return s.Serve(ctx, ln, grace)
+}
+
+// Serve atende requisições no listener informado até que o contexto seja
+// cancelado, encerrando graciosamente dentro do período de graça; usa TLS
+// quando o http.Server tiver TLSConfig definido
+func (s *Server) Serve(ctx context.Context, ln net.Listener, grace time.Duration) (*ShutdownReport, error) {
+	if s.ConnLimiter != nil {
+		ln = s.ConnLimiter.Listener(ln, s.srv.TLSConfig == nil)
+		limiter, next := s.ConnLimiter, s.srv.ConnState
+		s.srv.ConnState = func(conn net.Conn, state http.ConnState) {
+			limiter.ConnState(conn, state)
+			if next != nil {
+				next(conn, state)
+			}
+		}
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		if s.srv.TLSConfig != nil {
+			// Certificados já carregados em TLSConfig
+			errCh <- s.srv.ServeTLS(ln, "", "")
+			return
+		}
+		errCh <- s.srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil, nil
+		}
+		return nil, err
+	case <-ctx.Done():
+	}
+
+	return s.Shutdown(grace)
+}
+
+// Shutdown para de aceitar conexões e aguarda as requisições ativas até o
+// prazo informado; ao atingir o prazo, as conexões restantes são fechadas
+func (s *Server) Shutdown(grace time.Duration) (*ShutdownReport, error) {
+	start := time.Now()
+	report := &ShutdownReport{InFlight: s.inFlight.Load()}
+	if s.BeforeShutdown != nil {
+		s.BeforeShutdown(report.InFlight)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	err := s.srv.Shutdown(shutdownCtx)
+	report.Remaining = s.inFlight.Load()
+	if errors.Is(err, context.DeadlineExceeded) {
+		report.DeadlineExceeded = true
+		err = s.srv.Close()
+	}
+
+	report.Drained = report.InFlight - report.Remaining
+	if report.Drained < 0 {
+		report.Drained = 0
+	}
+	report.Duration = time.Since(start)
+
+	return report, err
+}
+
+// track conta as requisições em andamento
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
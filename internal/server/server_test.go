@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startBlockingServer inicia um servidor cujo handler bloqueia até release ser fechado
+func startBlockingServer(t *testing.T, grace time.Duration) (addr string, started, release chan struct{}, cancel context.CancelFunc, result chan *ShutdownReport) {
+	t.Helper()
+
+	started = make(chan struct{}, 1)
+	release = make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := New(&http.Server{Handler: mux})
+	ctx, cancel := context.WithCancel(context.Background())
+	result = make(chan *ShutdownReport, 1)
+	go func() {
+		report, err := srv.Serve(ctx, ln, grace)
+		assert.NoError(t, err)
+		result <- report
+	}()
+
+	return ln.Addr().String(), started, release, cancel, result
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	addr, started, release, cancel, result := startBlockingServer(t, 2*time.Second)
+
+	respCh := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/")
+		if err != nil {
+			respCh <- 0
+			return
+		}
+		resp.Body.Close()
+		respCh <- resp.StatusCode
+	}()
+
+	<-started
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	report := <-result
+	require.NotNil(t, report)
+	assert.Equal(t, int64(1), report.InFlight)
+	assert.Equal(t, int64(1), report.Drained)
+	assert.Equal(t, int64(0), report.Remaining)
+	assert.False(t, report.DeadlineExceeded)
+	assert.Equal(t, http.StatusOK, <-respCh)
+}
+
+func TestServeReportsDeadlineExceeded(t *testing.T) {
+	addr, started, release, cancel, result := startBlockingServer(t, 100*time.Millisecond)
+	defer close(release)
+
+	go func() {
+		resp, err := http.Get("http://" + addr + "/")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	report := <-result
+	require.NotNil(t, report)
+	assert.True(t, report.DeadlineExceeded)
+	assert.Equal(t, int64(1), report.Remaining)
+	assert.Equal(t, int64(0), report.Drained)
+}
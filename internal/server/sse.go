@@ -0,0 +1,198 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Parâmetros do stream de Server-Sent Events
+const (
+	sseHeartbeat  = 15 * time.Second
+	sseSendBuffer = 64 // eventos pendentes por cliente
+)
+
+// SSEEvent evento enviado aos clientes de /sse
+type SSEEvent struct {
+	ID    string `json:"id,omitempty"`
+	Event string `json:"event,omitempty"`
+	Data  string `json:"data"`
+}
+
+// ParseSSEEvent interpreta uma linha de evento: um objeto JSON com id, event e
+// data, ou o texto da linha como data
+func ParseSSEEvent(line string) SSEEvent {
+	var e struct {
+		ID    string          `json:"id"`
+		Event string          `json:"event"`
+		Data  json.RawMessage `json:"data"`
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "{") && json.Unmarshal([]byte(line), &e) == nil && e.Data != nil {
+		data := string(e.Data)
+		// Strings JSON viram texto; objetos e números seguem como JSON
+		var s string
+		if json.Unmarshal(e.Data, &s) == nil {
+			data = s
+		}
+		return SSEEvent{ID: e.ID, Event: e.Event, Data: data}
+	}
+	return SSEEvent{Data: line}
+}
+
+// write serializa o evento no formato text/event-stream
+func (e SSEEvent) write(w io.Writer) error {
+	var b strings.Builder
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// SSEBroker distribui eventos a todos os clientes conectados em /sse
+type SSEBroker struct {
+	mu      sync.Mutex
+	clients map[chan SSEEvent]struct{}
+	seq     int64
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewSSEBroker cria o broker sem clientes
+func NewSSEBroker() *SSEBroker {
+	return &SSEBroker{clients: make(map[chan SSEEvent]struct{}), closed: make(chan struct{})}
+}
+
+// Clients retorna o número de clientes conectados
+func (b *SSEBroker) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+// Publish envia o evento a todos os clientes, numerando-o se não tiver ID;
+// clientes lentos demais perdem o evento
+func (b *SSEBroker) Publish(e SSEEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	if e.ID == "" {
+		e.ID = strconv.FormatInt(b.seq, 10)
+	}
+	for ch := range b.clients {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Close encerra os streams abertos
+func (b *SSEBroker) Close() {
+	b.once.Do(func() { close(b.closed) })
+}
+
+func (b *SSEBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// Streams longos não podem respeitar o WriteTimeout do servidor
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, ": conectado\n\n"); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ch := make(chan SSEEvent, sseSendBuffer)
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, ch)
+		b.mu.Unlock()
+	}()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case e := <-ch:
+			err = e.write(w)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case <-r.Context().Done():
+			return
+		case <-b.closed:
+			return
+		}
+		if err != nil || rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// Tick publica um evento "tick" com o número e o horário a cada intervalo, até o
+// contexto ser cancelado
+func (b *SSEBroker) Tick(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for n := 1; ; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			data, _ := json.Marshal(map[string]interface{}{"n": n, "time": now.Format(time.RFC3339Nano)})
+			b.Publish(SSEEvent{Event: "tick", Data: string(data)})
+		}
+	}
+}
+
+// Stream publica cada linha não vazia do leitor como um evento (veja
+// ParseSSEEvent), aguardando o intervalo entre as linhas quando positivo;
+// retorna ao fim do leitor ou quando o contexto é cancelado, com o número de
+// eventos publicados
+func (b *SSEBroker) Stream(ctx context.Context, r io.Reader, interval time.Duration) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	n := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if interval > 0 {
+			select {
+			case <-ctx.Done():
+				return n, ctx.Err()
+			case <-time.After(interval):
+			}
+		} else if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		b.Publish(ParseSSEEvent(line))
+		n++
+	}
+	return n, scanner.Err()
+}
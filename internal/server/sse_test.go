@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSSEEvent(t *testing.T) {
+	assert.Equal(t, SSEEvent{Data: "texto simples"}, ParseSSEEvent("texto simples"))
+	assert.Equal(t, SSEEvent{ID: "7", Event: "pedido", Data: `{"total":10}`},
+		ParseSSEEvent(`{"id": "7", "event": "pedido", "data": {"total":10}}`))
+	assert.Equal(t, SSEEvent{Event: "aviso", Data: "olá"}, ParseSSEEvent(`{"event": "aviso", "data": "olá"}`))
+	// JSON sem data é enviado como está
+	assert.Equal(t, SSEEvent{Data: `{"total": 10}`}, ParseSSEEvent(`{"total": 10}`))
+}
+
+func TestSSEEventWrite(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, SSEEvent{ID: "1", Event: "log", Data: "linha 1\nlinha 2"}.write(&b))
+	assert.Equal(t, "id: 1\nevent: log\ndata: linha 1\ndata: linha 2\n\n", b.String())
+}
+
+// readSSEEvents lê n eventos (blocos separados por linha em branco, sem comentários)
+func readSSEEvents(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+	var events []string
+	var block strings.Builder
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		switch {
+		case line == "\n":
+			if block.Len() > 0 {
+				events = append(events, block.String())
+				block.Reset()
+			}
+		case strings.HasPrefix(line, ":"):
+		default:
+			block.WriteString(line)
+		}
+	}
+	return events
+}
+
+func TestSSEBrokerStream(t *testing.T) {
+	broker := NewSSEBroker()
+	srv := httptest.NewServer(broker)
+	defer srv.Close()
+	defer broker.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Eventually(t, func() bool { return broker.Clients() == 1 }, time.Second, 10*time.Millisecond)
+
+	n, err := broker.Stream(context.Background(), strings.NewReader("primeiro\n\n{\"event\": \"fim\", \"data\": \"ok\"}\n"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	events := readSSEEvents(t, bufio.NewReader(resp.Body), 2)
+	assert.Equal(t, "id: 1\ndata: primeiro\n", events[0])
+	assert.Equal(t, "id: 2\nevent: fim\ndata: ok\n", events[1])
+
+	broker.Close()
+	assert.Eventually(t, func() bool { return broker.Clients() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSSEBrokerTick(t *testing.T) {
+	broker := NewSSEBroker()
+	srv := httptest.NewServer(broker)
+	defer srv.Close()
+	defer broker.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	go broker.Tick(ctx, 10*time.Millisecond)
+
+	events := readSSEEvents(t, bufio.NewReader(resp.Body), 2)
+	assert.Contains(t, events[0], "event: tick\n")
+	assert.Contains(t, events[0], `"n":`)
+}
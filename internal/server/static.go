@@ -0,0 +1,308 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// indexFile nome do arquivo servido como índice de diretórios
+const indexFile = "index.html"
+
+// StaticOptions opções do servidor de arquivos estáticos
+type StaticOptions struct {
+	Listing bool // gera listagem HTML para diretórios sem index.html
+	SPA     bool // responde index.html da raiz para rotas desconhecidas
+}
+
+// StaticHandler serve uma árvore de diretórios com detecção de Content-Type,
+// requisições Range e cache via ETag/Last-Modified
+type StaticHandler struct {
+	root string
+	opts StaticOptions
+}
+
+// NewStaticHandler cria um StaticHandler para o diretório root
+func NewStaticHandler(root string, opts StaticOptions) (*StaticHandler, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao resolver diretório %s: %w", root, err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao resolver diretório %s: %w", root, err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao acessar diretório %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s não é um diretório", root)
+	}
+	return &StaticHandler{root: resolved, opts: opts}, nil
+}
+
+// Root retorna o caminho absoluto do diretório servido
+func (h *StaticHandler) Root() string {
+	return h.root
+}
+
+func (h *StaticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	upath := r.URL.Path
+	if !strings.HasPrefix(upath, "/") {
+		upath = "/" + upath
+	}
+	if strings.ContainsAny(upath, "\\\x00") {
+		http.Error(w, "caminho inválido", http.StatusBadRequest)
+		return
+	}
+	clean := path.Clean(upath)
+	if h.opts.Listing && clean == "/"+listingStyleFile {
+		serveAsset("text/css; charset=utf-8", listingStyle)(w, r)
+		return
+	}
+	if hasDotSegment(clean) {
+		http.NotFound(w, r)
+		return
+	}
+
+	full, err := h.resolve(clean)
+	if err != nil {
+		// ENOTDIR: caminho abaixo de um arquivo, como /index.html/x
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			h.notFound(w, r, clean)
+			return
+		}
+		if errors.Is(err, fs.ErrPermission) {
+			http.Error(w, "acesso negado", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	info, err := os.Stat(full)
+	if err != nil {
+		h.notFound(w, r, clean)
+		return
+	}
+
+	if !info.IsDir() {
+		h.serveFile(w, r, full)
+		return
+	}
+
+	if !strings.HasSuffix(upath, "/") {
+		target := upath + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	index := filepath.Join(full, indexFile)
+	if fi, err := os.Stat(index); err == nil && !fi.IsDir() {
+		h.serveFile(w, r, index)
+		return
+	}
+
+	if h.opts.Listing {
+		h.serveListing(w, r, full, clean)
+		return
+	}
+	http.Error(w, "listagem de diretório desabilitada", http.StatusForbidden)
+}
+
+// resolve converte o caminho da URL em caminho no disco, recusando links
+// simbólicos que apontem para fora da raiz
+func (h *StaticHandler) resolve(clean string) (string, error) {
+	full := filepath.Join(h.root, filepath.FromSlash(clean))
+	resolved, err := filepath.EvalSymlinks(full)
+	if err != nil {
+		return "", err
+	}
+	if !isWithin(h.root, resolved) {
+		return "", fs.ErrPermission
+	}
+	return resolved, nil
+}
+
+// notFound responde 404 ou, no modo SPA, o index.html da raiz para rotas sem extensão
+func (h *StaticHandler) notFound(w http.ResponseWriter, r *http.Request, clean string) {
+	if h.opts.SPA && path.Ext(clean) == "" {
+		index := filepath.Join(h.root, indexFile)
+		if fi, err := os.Stat(index); err == nil && !fi.IsDir() {
+			h.serveFile(w, r, index)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// serveFile envia o arquivo com ETag fraco derivado de tamanho e data de modificação;
+// http.ServeContent cuida de Content-Type, Range e requisições condicionais
+func (h *StaticHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	f, err := os.Open(name) //nolint:gosec // caminho validado em resolve
+	if err != nil {
+		http.Error(w, "acesso negado", http.StatusForbidden)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", fmt.Sprintf(`W/"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// listingEntry entrada exibida na listagem de diretório
+type listingEntry struct {
+	Name    string
+	Href    string
+	Size    string
+	ModTime string
+	IsDir   bool
+}
+
+// listingStyleFile folha de estilo da listagem, servida na raiz e na mesma
+// origem para funcionar com a Content-Security-Policy padrão (default-src 'self')
+const listingStyleFile = "__listing.css"
+
+// listingStyle folha de estilo da listagem de diretório
+const listingStyle = `body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { padding: 0.2em 1em; text-align: left; }
+td.size { text-align: right; }
+`
+
+var listingTemplate = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Índice de {{.Path}}</title>
+<link rel="stylesheet" href="{{.Root}}` + listingStyleFile + `">
+</head>
+<body>
+<h1>Índice de {{.Path}}</h1>
+<table>
+<tr><th>Nome</th><th>Tamanho</th><th>Modificado</th></tr>
+{{if .Parent}}<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{end}}{{range .Entries}}<tr><td><a href="{{.Href}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td class="size">{{.Size}}</td><td>{{.ModTime}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// serveListing gera a listagem HTML de um diretório, ocultando arquivos ocultos
+func (h *StaticHandler) serveListing(w http.ResponseWriter, r *http.Request, dir, clean string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		http.Error(w, "erro ao ler diretório", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]listingEntry, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		item := listingEntry{
+			Name:    entry.Name(),
+			Href:    url.PathEscape(entry.Name()),
+			ModTime: info.ModTime().Format(time.DateTime),
+			IsDir:   info.IsDir(),
+		}
+		if info.IsDir() {
+			item.Href += "/"
+			item.Size = "-"
+		} else {
+			item.Size = formatSize(info.Size())
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].IsDir != items[j].IsDir {
+			return items[i].IsDir
+		}
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	// A folha de estilo é referenciada a partir da raiz por caminho relativo,
+	// que continua válido com o handler montado em outro endpoint
+	root := ""
+	if clean != "/" {
+		root = strings.Repeat("../", strings.Count(clean, "/"))
+	}
+	data := struct {
+		Path    string
+		Root    string
+		Parent  bool
+		Entries []listingEntry
+	}{Path: clean, Root: root, Parent: clean != "/", Entries: items}
+	if err := listingTemplate.Execute(w, data); err != nil {
+		http.Error(w, "erro ao gerar listagem", http.StatusInternalServerError)
+	}
+}
+
+// hasDotSegment verifica se algum segmento do caminho começa com ponto
+func hasDotSegment(p string) bool {
+	for _, segment := range strings.Split(p, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin verifica se target está dentro de root
+func isWithin(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// formatSize formata um tamanho em bytes de forma legível
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
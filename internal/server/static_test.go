@@ -0,0 +1,149 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStaticFixture cria uma árvore de arquivos para os testes do StaticHandler
+func newStaticFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"index.html":       "<h1>home</h1>",
+		"app.js":           "console.log('ok')",
+		"docs/guide.txt":   "0123456789",
+		".env":             "SECRET=1",
+		"assets/.hidden/x": "x",
+		"assets/style.css": "body{}",
+	}
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	return root
+}
+
+func serveStatic(t *testing.T, h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStaticHandlerServesFiles(t *testing.T) {
+	h, err := NewStaticHandler(newStaticFixture(t), StaticOptions{})
+	require.NoError(t, err)
+
+	rec := serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/app.js", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "javascript")
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+
+	rec = serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+}
+
+func TestStaticHandlerRangeAndConditional(t *testing.T) {
+	h, err := NewStaticHandler(newStaticFixture(t), StaticOptions{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/guide.txt", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rec := serveStatic(t, h, req)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "2345", rec.Body.String())
+
+	etag := rec.Header().Get("ETag")
+	req = httptest.NewRequest(http.MethodGet, "/docs/guide.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = serveStatic(t, h, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestStaticHandlerRefusesDotfilesAndTraversal(t *testing.T) {
+	root := newStaticFixture(t)
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0644))
+	if err := os.Symlink(outside, filepath.Join(root, "link.txt")); err != nil {
+		t.Logf("symlink não suportado: %v", err)
+	}
+
+	h, err := NewStaticHandler(root, StaticOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/.env", http.StatusNotFound},
+		{"/assets/.hidden/x", http.StatusNotFound},
+		{"/../../etc/passwd", http.StatusNotFound},
+		{"/docs/..%5c..%5cetc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serveStatic(t, h, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+
+	if _, err := os.Lstat(filepath.Join(root, "link.txt")); err == nil {
+		rec := serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/link.txt", nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestStaticHandlerListingAndSPA(t *testing.T) {
+	root := newStaticFixture(t)
+
+	h, err := NewStaticHandler(root, StaticOptions{})
+	require.NoError(t, err)
+	rec := serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/assets/", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+
+	h, err = NewStaticHandler(root, StaticOptions{Listing: true, SPA: true})
+	require.NoError(t, err)
+	rec = serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/assets/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "style.css")
+	assert.NotContains(t, rec.Body.String(), ".hidden")
+	assertNoInlineCode(t, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `href="../__listing.css"`)
+	rec = serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/__listing.css", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	rec = serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+
+	rec = serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/missing.js", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Caminho abaixo de um arquivo também cai no fallback da SPA
+	rec = serveStatic(t, h, httptest.NewRequest(http.MethodGet, "/index.html/x", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+}
+
+func TestStaticHandlerPathBelowFile(t *testing.T) {
+	h, err := NewStaticHandler(newStaticFixture(t), StaticOptions{})
+	require.NoError(t, err)
+
+	for _, p := range []string{"/index.html/x", "/docs/guide.txt/a/b"} {
+		rec := serveStatic(t, h, httptest.NewRequest(http.MethodGet, p, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, p)
+	}
+}
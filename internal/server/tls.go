@@ -0,0 +1,183 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-bast-cli/internal/constants"
+)
+
+// Arquivos do certificado autoassinado guardados no diretório de cache
+const (
+	selfSignedCertFile = "localhost.pem"
+	selfSignedKeyFile  = "localhost-key.pem"
+	selfSignedValidity = 365 * 24 * time.Hour
+)
+
+// SelfSignedCert certificado autoassinado carregado ou gerado
+type SelfSignedCert struct {
+	Certificate tls.Certificate
+	Fingerprint string // SHA-256 do certificado em hexadecimal separado por ":"
+	Hosts       []string
+	CertPath    string
+	NotAfter    time.Time
+	Created     bool // indica se o certificado foi gerado nesta execução
+}
+
+// SelfSignedHosts retorna os nomes cobertos pelo certificado autoassinado:
+// localhost, 127.0.0.1, ::1 e o hostname da máquina
+func SelfSignedHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" && hostname != "localhost" {
+		hosts = append(hosts, hostname)
+	}
+	return hosts
+}
+
+// LoadOrCreateSelfSigned reutiliza o certificado em cache no diretório informado
+// se ainda for válido para os hosts, ou gera um novo certificado ECDSA P-256
+func LoadOrCreateSelfSigned(dir string, hosts []string) (*SelfSignedCert, error) {
+	certPath := filepath.Join(dir, selfSignedCertFile)
+	keyPath := filepath.Join(dir, selfSignedKeyFile)
+
+	if cert, err := loadSelfSigned(certPath, keyPath, hosts); err == nil {
+		return cert, nil
+	}
+
+	if err := os.MkdirAll(dir, constants.PrivateDirPerm); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de certificados: %w", err)
+	}
+
+	certPEM, keyPEM, err := generateSelfSigned(hosts)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyPath, keyPEM, constants.PrivateFilePerm); err != nil {
+		return nil, fmt.Errorf("erro ao salvar chave privada: %w", err)
+	}
+	if err := os.WriteFile(certPath, certPEM, constants.ConfigFilePerm); err != nil {
+		return nil, fmt.Errorf("erro ao salvar certificado: %w", err)
+	}
+
+	cert, err := loadSelfSigned(certPath, keyPath, hosts)
+	if err != nil {
+		return nil, err
+	}
+	cert.Created = true
+	return cert, nil
+}
+
+// loadSelfSigned carrega o par de chaves e verifica validade e hosts cobertos
+func loadSelfSigned(certPath, keyPath string, hosts []string) (*SelfSignedCert, error) {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if time.Until(leaf.NotAfter) < 24*time.Hour {
+		return nil, fmt.Errorf("certificado expira em %s", leaf.NotAfter.Format(time.DateOnly))
+	}
+	for _, h := range hosts {
+		if err := leaf.VerifyHostname(h); err != nil {
+			return nil, fmt.Errorf("certificado não cobre %s", h)
+		}
+	}
+	pair.Leaf = leaf
+
+	return &SelfSignedCert{
+		Certificate: pair,
+		Fingerprint: Fingerprint(leaf),
+		Hosts:       hosts,
+		CertPath:    certPath,
+		NotAfter:    leaf.NotAfter,
+	}, nil
+}
+
+// generateSelfSigned gera um certificado ECDSA P-256 para os hosts informados
+func generateSelfSigned(hosts []string) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao gerar chave: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao gerar número de série: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{constants.AppName + " dev"}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao criar certificado: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao serializar chave: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// Fingerprint retorna o SHA-256 do certificado no formato AA:BB:...
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// RedirectToHTTPS retorna um handler que redireciona para o mesmo host na porta HTTPS
+func RedirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		// IPv6 sem porta ("[::1]") chega com colchetes, que JoinHostPort acrescenta
+		host = strings.Trim(host, "[]")
+		authority := net.JoinHostPort(host, httpsPort)
+		if httpsPort == "443" {
+			authority = strings.TrimSuffix(authority, ":443")
+		}
+		target := "https://" + authority + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
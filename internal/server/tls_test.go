@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrCreateSelfSigned(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+
+	first, err := LoadOrCreateSelfSigned(dir, hosts)
+	require.NoError(t, err)
+	assert.True(t, first.Created)
+	assert.Len(t, first.Fingerprint, 32*3-1)
+	assert.Equal(t, x509.ECDSA, first.Certificate.Leaf.PublicKeyAlgorithm)
+	for _, h := range hosts {
+		assert.NoError(t, first.Certificate.Leaf.VerifyHostname(h))
+	}
+
+	info, err := os.Stat(filepath.Join(dir, selfSignedKeyFile))
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	second, err := LoadOrCreateSelfSigned(dir, hosts)
+	require.NoError(t, err)
+	assert.False(t, second.Created)
+	assert.Equal(t, first.Fingerprint, second.Fingerprint)
+
+	// Um host novo invalida o certificado em cache
+	third, err := LoadOrCreateSelfSigned(dir, append(hosts, "bast.test"))
+	require.NoError(t, err)
+	assert.True(t, third.Created)
+	assert.NotEqual(t, first.Fingerprint, third.Fingerprint)
+}
+
+func TestServeWithTLS(t *testing.T) {
+	cert, err := LoadOrCreateSelfSigned(t.TempDir(), []string{"127.0.0.1"})
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := New(&http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "seguro")
+		}),
+		TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert.Certificate}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = srv.Serve(ctx, ln, time.Second)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Certificate.Leaf)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}}
+
+	resp, err := client.Get("https://" + ln.Addr().String() + "/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "seguro", string(body))
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		host, port, want string
+	}{
+		{"localhost:8000", "8443", "https://localhost:8443/a?b=1"},
+		{"example.test", "443", "https://example.test/a?b=1"},
+		{"[::1]:8000", "8443", "https://[::1]:8443/a?b=1"},
+		{"[::1]", "8443", "https://[::1]:8443/a?b=1"},
+		{"[::1]", "443", "https://[::1]/a?b=1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/a?b=1", nil)
+		req.Host = tt.host
+		rec := httptest.NewRecorder()
+		RedirectToHTTPS(tt.port).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+		assert.Equal(t, tt.want, rec.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxUploadNameLength tamanho máximo, em bytes, do nome de um arquivo recebido
+const maxUploadNameLength = 200
+
+// maxUploadCollisions quantidade de sufixos tentados antes de desistir do nome
+const maxUploadCollisions = 10000
+
+// errUploadTooLarge arquivo acima do limite configurado
+var errUploadTooLarge = errors.New("arquivo excede o tamanho máximo")
+
+// UploadedFile arquivo gravado pelo modo de upload
+type UploadedFile struct {
+	Name     string    `json:"name"`     // nome gravado no diretório
+	Original string    `json:"original"` // nome enviado pelo cliente
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"`
+	Time     time.Time `json:"time"`
+}
+
+// UploadHandler recebe arquivos por multipart (POST) ou corpo bruto (PUT /nome)
+// e os grava em um diretório, calculando o SHA-256 durante a cópia
+type UploadHandler struct {
+	// OnUpload é chamado, se definido, após cada arquivo gravado
+	OnUpload func(file UploadedFile)
+
+	dir     string
+	maxSize int64
+}
+
+// NewUploadHandler cria o handler para o diretório dir (criado se não existir);
+// maxSize limita cada arquivo, com 0 para ilimitado
+func NewUploadHandler(dir string, maxSize int64) (*UploadHandler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de upload: %w", err)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao resolver diretório %s: %w", dir, err)
+	}
+	return &UploadHandler{dir: abs, maxSize: maxSize}, nil
+}
+
+// Dir retorna o caminho absoluto do diretório de destino
+func (h *UploadHandler) Dir() string {
+	return h.dir
+}
+
+func (h *UploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		switch r.URL.Path {
+		case "/", "":
+			serveAsset("text/html; charset=utf-8", uploadPage)(w, r)
+		case "/" + uploadStyleFile:
+			serveAsset("text/css; charset=utf-8", uploadStyle)(w, r)
+		case "/" + uploadScriptFile:
+			serveAsset("text/javascript; charset=utf-8", uploadScript)(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	case http.MethodPost, http.MethodPut:
+		// Envios grandes não podem respeitar os timeouts de leitura e escrita do servidor
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+		if r.Method == http.MethodPost {
+			h.serveMultipart(w, r)
+		} else {
+			h.servePut(w, r)
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT")
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// serveMultipart grava cada parte com arquivo do formulário multipart
+func (h *UploadHandler) serveMultipart(w http.ResponseWriter, r *http.Request) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "envie um formulário multipart/form-data ou use PUT /nome-do-arquivo"})
+		return
+	}
+
+	files := []UploadedFile{}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			h.fail(w, files, err)
+			return
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		file, err := h.save(part.FileName(), part)
+		part.Close()
+		if err != nil {
+			h.fail(w, files, err)
+			return
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "nenhum arquivo enviado"})
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"files": files})
+}
+
+// servePut grava o corpo da requisição com o nome do caminho
+func (h *UploadHandler) servePut(w http.ResponseWriter, r *http.Request) {
+	name := path.Base(r.URL.Path)
+	if name == "/" || name == "." {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "informe o nome do arquivo no caminho: PUT /nome-do-arquivo"})
+		return
+	}
+	file, err := h.save(name, r.Body)
+	if err != nil {
+		h.fail(w, nil, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, file)
+}
+
+// fail responde o erro de gravação, informando os arquivos já gravados
+func (h *UploadHandler) fail(w http.ResponseWriter, saved []UploadedFile, err error) {
+	status := http.StatusInternalServerError
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.Is(err, errUploadTooLarge), errors.As(err, &maxBytes):
+		status = http.StatusRequestEntityTooLarge
+		err = errUploadTooLarge
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		status = http.StatusBadRequest
+	}
+	body := map[string]interface{}{"error": err.Error()}
+	if len(saved) > 0 {
+		body["files"] = saved
+	}
+	writeJSON(w, status, body)
+}
+
+// save copia o conteúdo para um novo arquivo com o nome sanitizado, sem
+// sobrescrever arquivos existentes; arquivos incompletos são removidos
+func (h *UploadHandler) save(original string, src io.Reader) (UploadedFile, error) {
+	f, name, err := createUnique(h.dir, SanitizeFilename(original))
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	hash := sha256.New()
+	limited := src
+	if h.maxSize > 0 {
+		limited = io.LimitReader(src, h.maxSize+1)
+	}
+	size, err := io.Copy(io.MultiWriter(f, hash), limited)
+	if err == nil && h.maxSize > 0 && size > h.maxSize {
+		err = errUploadTooLarge
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(filepath.Join(h.dir, name))
+		return UploadedFile{}, err
+	}
+
+	file := UploadedFile{
+		Name:     name,
+		Original: original,
+		Size:     size,
+		SHA256:   hex.EncodeToString(hash.Sum(nil)),
+		Time:     time.Now(),
+	}
+	if h.OnUpload != nil {
+		h.OnUpload(file)
+	}
+	return file, nil
+}
+
+// createUnique cria dir/name de forma exclusiva, acrescentando -1, -2... antes
+// da extensão quando o nome já existe
+func createUnique(dir, name string) (*os.File, string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; i <= maxUploadCollisions; i++ {
+		f, err := os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return f, candidate, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, "", fmt.Errorf("erro ao criar arquivo: %w", err)
+		}
+		candidate = stem + "-" + strconv.Itoa(i) + ext
+	}
+	return nil, "", fmt.Errorf("nomes disponíveis esgotados para %s", name)
+}
+
+// SanitizeFilename reduz o nome enviado pelo cliente a um nome de arquivo seguro:
+// sem diretórios, sem ponto inicial (arquivos ocultos), apenas letras, dígitos,
+// '.', '-' e '_' (demais caracteres viram '_') e com no máximo 200 bytes
+func SanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	clean := strings.TrimLeft(b.String(), ".")
+	clean = strings.TrimRight(clean, ".")
+	if strings.Trim(clean, "_") == "" {
+		clean = "upload"
+	}
+
+	if len(clean) > maxUploadNameLength {
+		ext := filepath.Ext(clean)
+		if len(ext) > maxUploadNameLength/4 {
+			ext = ""
+		}
+		stem := clean[:maxUploadNameLength-len(ext)]
+		for !utf8.ValidString(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		clean = stem + ext
+	}
+	return clean
+}
+
+// Caminhos dos recursos da página de upload, servidos na mesma origem para
+// funcionar com a Content-Security-Policy padrão (default-src 'self'); a página
+// os referencia por caminho relativo, para valer também com --endpoint
+const (
+	uploadStyleFile  = "__upload.css"
+	uploadScriptFile = "__upload.js"
+)
+
+// uploadPage página HTML para enviar arquivos pelo navegador
+const uploadPage = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>bast upload</title>
+<link rel="stylesheet" href="` + uploadStyleFile + `">
+</head>
+<body>
+<h1>Enviar arquivos</h1>
+<form id="drop" method="post" enctype="multipart/form-data">
+<p>Arraste arquivos para cá ou escolha abaixo</p>
+<input type="file" name="file" multiple>
+<button type="submit">Enviar</button>
+</form>
+<ul id="files"></ul>
+<script src="` + uploadScriptFile + `"></script>
+</body>
+</html>
+`
+
+// uploadStyle folha de estilo da página de upload
+const uploadStyle = `body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 40rem; padding: 0 1rem; color: #222; }
+#drop { border: 2px dashed #999; border-radius: 8px; padding: 2rem; text-align: center; }
+#drop.over { border-color: #2a7ae2; background: #f0f6ff; }
+ul { padding: 0; list-style: none; }
+li { padding: .4rem 0; border-bottom: 1px solid #eee; word-break: break-all; }
+code { font-size: .8rem; color: #555; }
+.erro { color: #b00020; }
+`
+
+// uploadScript script da página de upload
+const uploadScript = `(function () {
+  var form = document.getElementById("drop"), list = document.getElementById("files");
+  function item(text, cls) {
+    var li = document.createElement("li");
+    li.textContent = text;
+    if (cls) li.className = cls;
+    list.prepend(li);
+    return li;
+  }
+  function send(files) {
+    Array.prototype.forEach.call(files, function (file) {
+      var li = item(file.name + ": enviando...");
+      var data = new FormData();
+      data.append("file", file);
+      var xhr = new XMLHttpRequest();
+      xhr.open("POST", location.pathname);
+      xhr.upload.onprogress = function (e) {
+        if (e.lengthComputable) li.textContent = file.name + ": " + Math.round(e.loaded * 100 / e.total) + "%";
+      };
+      xhr.onload = function () {
+        var res = {};
+        try { res = JSON.parse(xhr.responseText); } catch (e) {}
+        if (xhr.status !== 201) {
+          li.textContent = file.name + ": " + (res.error || xhr.status);
+          li.className = "erro";
+          return;
+        }
+        var saved = res.files[0];
+        li.textContent = saved.name + " (" + saved.size + " bytes) ";
+        var code = document.createElement("code");
+        code.textContent = "sha256 " + saved.sha256;
+        li.appendChild(code);
+      };
+      xhr.onerror = function () { li.textContent = file.name + ": falha de conexão"; li.className = "erro"; };
+      xhr.send(data);
+    });
+  }
+  form.addEventListener("submit", function (e) {
+    e.preventDefault();
+    send(form.elements.file.files);
+    form.reset();
+  });
+  form.addEventListener("dragover", function (e) { e.preventDefault(); form.classList.add("over"); });
+  form.addEventListener("dragleave", function () { form.classList.remove("over"); });
+  form.addEventListener("drop", function (e) {
+    e.preventDefault();
+    form.classList.remove("over");
+    send(e.dataTransfer.files);
+  });
+})();
+`
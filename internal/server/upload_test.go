@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// multipartRequest monta um POST multipart com os arquivos (nome -> conteúdo) na ordem dada
+func multipartRequest(t *testing.T, files ...[2]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("comentario", "ignorado"))
+	for _, f := range files {
+		part, err := mw.CreateFormFile("file", f[0])
+		require.NoError(t, err)
+		_, err = part.Write([]byte(f[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	cases := map[string]string{
+		"foto.jpg":                   "foto.jpg",
+		"../../etc/passwd":           "passwd",
+		`C:\Users\ana\relatório.pdf`: "relatório.pdf",
+		".bashrc":                    "bashrc",
+		"meu arquivo (1).txt":        "meu_arquivo__1_.txt",
+		"..":                         "upload",
+		"":                           "upload",
+		"a\x00b.txt":                 "a_b.txt",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, SanitizeFilename(in), "entrada %q", in)
+	}
+
+	long := SanitizeFilename(strings.Repeat("é", 150) + ".tar.gz")
+	assert.LessOrEqual(t, len(long), maxUploadNameLength)
+	assert.True(t, strings.HasSuffix(long, ".gz"))
+}
+
+func TestUploadMultipart(t *testing.T) {
+	dir := t.TempDir()
+	h, err := NewUploadHandler(dir, 0)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("existente"), 0644))
+
+	var uploaded []string
+	h.OnUpload = func(file UploadedFile) { uploaded = append(uploaded, file.Name) }
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, multipartRequest(t, [2]string{"a.txt", "primeiro"}, [2]string{"../b.txt", "segundo"}))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var res struct {
+		Files []UploadedFile `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	require.Len(t, res.Files, 2)
+	// Nomes existentes não são sobrescritos
+	assert.Equal(t, "a-1.txt", res.Files[0].Name)
+	assert.Equal(t, "a.txt", res.Files[0].Original)
+	assert.Equal(t, int64(8), res.Files[0].Size)
+	assert.Equal(t, sha256Hex("primeiro"), res.Files[0].SHA256)
+	assert.Equal(t, "b.txt", res.Files[1].Name)
+	assert.Equal(t, []string{"a-1.txt", "b.txt"}, uploaded)
+
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "existente", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "a-1.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "primeiro", string(data))
+}
+
+func TestUploadPut(t *testing.T) {
+	dir := t.TempDir()
+	h, err := NewUploadHandler(dir, 0)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/notas%20finais.md", strings.NewReader("# oi")))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var file UploadedFile
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &file))
+	assert.Equal(t, "notas_finais.md", file.Name)
+	assert.Equal(t, sha256Hex("# oi"), file.SHA256)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader("x")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUploadMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	h, err := NewUploadHandler(dir, 4)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/ok.bin", strings.NewReader("1234")))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, multipartRequest(t, [2]string{"c.bin", "abc"}, [2]string{"grande.bin", "12345"}))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"c.bin"`)
+
+	// Arquivos incompletos são removidos
+	assert.NoFileExists(t, filepath.Join(dir, "grande.bin"))
+	assert.FileExists(t, filepath.Join(dir, "c.bin"))
+}
+
+func TestUploadPage(t *testing.T) {
+	h, err := NewUploadHandler(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `enctype="multipart/form-data"`)
+	assertNoInlineCode(t, rec.Body.String())
+
+	for path, contentType := range map[string]string{
+		"/__upload.css": "text/css; charset=utf-8",
+		"/__upload.js":  "text/javascript; charset=utf-8",
+	} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, contentType, rec.Header().Get("Content-Type"), path)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Parâmetros das conexões WebSocket
+const (
+	wsMaxMessage   = 1 << 20 // tamanho máximo de uma mensagem recebida
+	wsPingInterval = 30 * time.Second
+	wsPongTimeout  = 2 * wsPingInterval
+	wsWriteTimeout = 10 * time.Second
+	wsSendBuffer   = 64 // mensagens pendentes por cliente do broadcast
+)
+
+// wsUpgrader aceita qualquer origem: os endpoints servem de backend local para
+// front-ends em outras portas; use server.auth para restringir o acesso
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsMessage mensagem recebida ou enviada, com o tipo original (texto ou binário)
+type wsMessage struct {
+	kind int
+	data []byte
+}
+
+// wsClient conexão WebSocket com escrita serializada por uma goroutine
+type wsClient struct {
+	conn      *websocket.Conn
+	broadcast bool // participa de /ws/broadcast
+	send      chan wsMessage
+	done      chan struct{}
+	once      sync.Once
+}
+
+// WebSocketHub atende /ws/echo e /ws/broadcast e fecha as conexões em Close
+type WebSocketHub struct {
+	mu      sync.Mutex
+	clients map[*wsClient]struct{}
+	closed  bool
+}
+
+// NewWebSocketHub cria o hub sem conexões
+func NewWebSocketHub() *WebSocketHub {
+	return &WebSocketHub{clients: make(map[*wsClient]struct{})}
+}
+
+// Clients retorna o número de conexões abertas
+func (h *WebSocketHub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Close encerra todas as conexões e recusa novas
+func (h *WebSocketHub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	clients := make([]*wsClient, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+	for _, c := range clients {
+		c.close(websocket.CloseGoingAway, "servidor encerrando")
+	}
+}
+
+// EchoHandler devolve cada mensagem recebida ao próprio cliente
+func (h *WebSocketHub) EchoHandler() http.Handler {
+	return h.handler(false, func(c *wsClient, msg wsMessage) {
+		c.enqueue(msg)
+	})
+}
+
+// BroadcastHandler repassa cada mensagem recebida a todos os clientes de
+// /ws/broadcast, inclusive o remetente
+func (h *WebSocketHub) BroadcastHandler() http.Handler {
+	return h.handler(true, func(_ *wsClient, msg wsMessage) {
+		h.broadcast(msg)
+	})
+}
+
+// broadcast enfileira a mensagem para os clientes de /ws/broadcast
+func (h *WebSocketHub) broadcast(msg wsMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if c.broadcast {
+			c.enqueue(msg)
+		}
+	}
+}
+
+// handler faz o upgrade e lê mensagens até a conexão fechar
+func (h *WebSocketHub) handler(broadcast bool, onMessage func(*wsClient, wsMessage)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			http.Error(w, "este endpoint requer uma conexão WebSocket", http.StatusUpgradeRequired)
+			return
+		}
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// O Upgrader já respondeu com o erro
+			return
+		}
+		// O servidor pode ter definido prazos para a requisição HTTP original
+		_ = conn.NetConn().SetDeadline(time.Time{})
+
+		c := &wsClient{conn: conn, broadcast: broadcast, send: make(chan wsMessage, wsSendBuffer), done: make(chan struct{})}
+		if !h.add(c) {
+			c.close(websocket.CloseGoingAway, "servidor encerrando")
+			return
+		}
+		defer h.remove(c)
+
+		go c.writeLoop()
+		c.readLoop(func(msg wsMessage) { onMessage(c, msg) })
+	})
+}
+
+func (h *WebSocketHub) add(c *wsClient) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.clients[c] = struct{}{}
+	return true
+}
+
+func (h *WebSocketHub) remove(c *wsClient) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+	c.close(websocket.CloseNormalClosure, "")
+}
+
+// readLoop lê mensagens até erro ou fechamento, renovando o prazo a cada pong
+func (c *wsClient) readLoop(onMessage func(wsMessage)) {
+	c.conn.SetReadLimit(wsMaxMessage)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	for {
+		kind, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		onMessage(wsMessage{kind: kind, data: data})
+	}
+}
+
+// writeLoop envia as mensagens enfileiradas e pings periódicos
+func (c *wsClient) writeLoop() {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteMessage(msg.kind, msg.data); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// enqueue agenda o envio; clientes lentos demais perdem a mensagem
+func (c *wsClient) enqueue(msg wsMessage) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	default:
+	}
+}
+
+// close envia o frame de fechamento e encerra a conexão uma única vez
+func (c *wsClient) close(code int, reason string) {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+		c.conn.Close()
+	})
+}
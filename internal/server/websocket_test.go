@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWebSocketServer(t *testing.T) (*WebSocketHub, string) {
+	t.Helper()
+	hub := NewWebSocketHub()
+	mux := http.NewServeMux()
+	mux.Handle("/ws/echo", hub.EchoHandler())
+	mux.Handle("/ws/broadcast", hub.BroadcastHandler())
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	t.Cleanup(hub.Close)
+	return hub, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func dialWebSocket(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return conn
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	_, base := newWebSocketServer(t)
+	conn := dialWebSocket(t, base+"/ws/echo")
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("olá")))
+	kind, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, kind)
+	assert.Equal(t, "olá", string(data))
+
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2, 3}))
+	kind, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, kind)
+	assert.Equal(t, []byte{1, 2, 3}, data)
+}
+
+func TestWebSocketBroadcast(t *testing.T) {
+	hub, base := newWebSocketServer(t)
+	a := dialWebSocket(t, base+"/ws/broadcast")
+	b := dialWebSocket(t, base+"/ws/broadcast")
+	echo := dialWebSocket(t, base+"/ws/echo")
+	require.Eventually(t, func() bool { return hub.Clients() == 3 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, a.WriteMessage(websocket.TextMessage, []byte("para todos")))
+	for _, conn := range []*websocket.Conn{a, b} {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "para todos", string(data))
+	}
+
+	// Clientes do echo não participam do broadcast
+	_ = echo.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, err := echo.ReadMessage()
+	assert.Error(t, err)
+}
+
+func TestWebSocketHubClose(t *testing.T) {
+	hub, base := newWebSocketServer(t)
+	conn := dialWebSocket(t, base+"/ws/echo")
+	require.Eventually(t, func() bool { return hub.Clients() == 1 }, time.Second, 10*time.Millisecond)
+
+	hub.Close()
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "erro inesperado: %v", err)
+	assert.Eventually(t, func() bool { return hub.Clients() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketRequiresUpgrade(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewWebSocketHub().EchoHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws/echo", nil))
+	assert.Equal(t, http.StatusUpgradeRequired, rec.Code)
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/CristianSsousa/go-bast-cli/internal/constants"
 )
@@ -65,3 +67,30 @@ func IsDir(path string) bool {
 func GetOS() string {
 	return runtime.GOOS
 }
+
+// ParseSize converte tamanhos como "512", "64KB", "10MB" ou "1GiB" em bytes
+// (múltiplos de 1024)
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		factor int64
+	}{
+		{"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.factor
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("tamanho inválido '%s': use bytes ou sufixos KB, MB, GB", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
@@ -73,3 +73,24 @@ func TestIsDir(t *testing.T) {
 		assert.False(t, IsDir("/tmp/nonexistent-dir-12345"))
 	})
 }
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":   512,
+		"64KB":  64 << 10,
+		"10 mb": 10 << 20,
+		"1.5M":  3 << 19,
+		"1GiB":  1 << 30,
+		"100B":  100,
+	}
+	for input, want := range tests {
+		got, err := ParseSize(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "dez", "-1MB", "10TB"} {
+		_, err := ParseSize(input)
+		assert.Error(t, err, input)
+	}
+}